@@ -0,0 +1,68 @@
+package errs
+
+import (
+	"errors"
+	"slices"
+	"sync"
+)
+
+type alias struct {
+	specific error
+	broad    error
+}
+
+var (
+	aliasMu sync.RWMutex
+	aliases []alias
+)
+
+// RegisterAlias makes errors matching specific also match broad, e.g.
+// RegisterAlias(ErrMissingArgument, ErrBadRequest) lets errors.Is(err, ErrBadRequest)
+// succeed for an error marked ErrMissingArgument. Aliases are transitive.
+// They are resolved by *Error, so the chain must contain an *Error.
+func RegisterAlias(specific, broad error) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	for _, a := range aliases {
+		if a.specific == specific && a.broad == broad {
+			return
+		}
+	}
+	aliases = append(aliases, alias{specific, broad})
+}
+
+// aliasesOf returns every sentinel registered, directly or transitively,
+// as implying target.
+func aliasesOf(target error) []error {
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+
+	if len(aliases) == 0 {
+		return nil
+	}
+	var specifics []error
+	queue := []error{target}
+	for len(queue) > 0 {
+		broad := queue[0]
+		queue = queue[1:]
+		for _, a := range aliases {
+			if a.broad != broad || a.specific == target || slices.Contains(specifics, a.specific) {
+				continue
+			}
+			specifics = append(specifics, a.specific)
+			queue = append(queue, a.specific)
+		}
+	}
+	return specifics
+}
+
+// matchesAlias reports whether e directly matches a sentinel implying target.
+func (e *Error) matchesAlias(target error) bool {
+	for _, specific := range aliasesOf(target) {
+		if e.HasMarker(specific) || errors.Is(e.Internal, specific) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package errs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestRegisterAlias(t *testing.T) {
+	errs.KeepRegistries(t)
+	errBadRequest := errors.New("bad request")
+	errClientFault := errors.New("client fault")
+	errs.RegisterAlias(errs.ErrMissingArgument, errBadRequest)
+	errs.RegisterAlias(errBadRequest, errClientFault)
+
+	t.Run("specific marker matches broad alias", func(t *testing.T) {
+		err := errs.Mark(errors.New("name is required"), errs.ErrMissingArgument)
+
+		if !errors.Is(err, errBadRequest) {
+			t.Error("errors.Is(err, errBadRequest) = false, want true")
+		}
+		if !errors.Is(errs.Wrap(err, "create user"), errBadRequest) {
+			t.Error("alias lost through Wrap")
+		}
+	})
+
+	t.Run("aliases are transitive", func(t *testing.T) {
+		err := errs.Mark(errors.New("name is required"), errs.ErrMissingArgument)
+
+		if !errors.Is(err, errClientFault) {
+			t.Error("errors.Is(err, errClientFault) = false, want true")
+		}
+	})
+
+	t.Run("broad does not imply specific", func(t *testing.T) {
+		err := errs.Mark(errors.New("bad payload"), errBadRequest)
+
+		if errors.Is(err, errs.ErrMissingArgument) {
+			t.Error("errors.Is(err, ErrMissingArgument) = true, want false")
+		}
+	})
+
+	t.Run("cyclic aliases terminate", func(t *testing.T) {
+		errA, errB := errors.New("a"), errors.New("b")
+		errs.RegisterAlias(errA, errB)
+		errs.RegisterAlias(errB, errA)
+
+		err := errs.Mark(errors.New("boom"), errA)
+		if !errors.Is(err, errB) {
+			t.Error("errors.Is(err, errB) = false, want true")
+		}
+		if errors.Is(err, errs.ErrNotFound) {
+			t.Error("errors.Is(err, ErrNotFound) = true, want false")
+		}
+	})
+}
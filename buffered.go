@@ -0,0 +1,91 @@
+package errs
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// BufferedResponseWriter holds the status, headers and body written to it
+// until Commit is called, so a handler that fails midway can still respond
+// with a clean error. HandleHTTPErr discards whatever was buffered before
+// rendering the error response.
+//
+// Wrap the writer in a middleware and call Commit once the handler returns.
+type BufferedResponseWriter struct {
+	w         http.ResponseWriter
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	committed bool
+	// handled records that HandleHTTPErr already wrote the response.
+	handled bool
+}
+
+// NewBufferedResponseWriter returns a writer buffering the response for w.
+func NewBufferedResponseWriter(w http.ResponseWriter) *BufferedResponseWriter {
+	return &BufferedResponseWriter{w: w, header: make(http.Header)}
+}
+
+// Header returns the buffered header map. After Commit it returns the
+// header map of the underlying writer.
+func (b *BufferedResponseWriter) Header() http.Header {
+	if b.committed {
+		return b.w.Header()
+	}
+	return b.header
+}
+
+// WriteHeader records the status. Only the first call has effect.
+func (b *BufferedResponseWriter) WriteHeader(status int) {
+	if b.committed {
+		b.w.WriteHeader(status)
+		return
+	}
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// Write buffers p. After Commit it writes through to the underlying writer.
+func (b *BufferedResponseWriter) Write(p []byte) (int, error) {
+	if b.committed {
+		return b.w.Write(p)
+	}
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// Reset discards the buffered status, headers and body.
+// It has no effect after Commit.
+func (b *BufferedResponseWriter) Reset() {
+	if b.committed {
+		return
+	}
+	b.header = make(http.Header)
+	b.status = 0
+	b.body.Reset()
+}
+
+// Commit sends the buffered response to the underlying writer.
+// Subsequent writes go directly to it. Calling Commit again is a no-op.
+func (b *BufferedResponseWriter) Commit() error {
+	if b.committed {
+		return nil
+	}
+	b.committed = true
+
+	dst := b.w.Header()
+	for key, values := range b.header {
+		dst[key] = values
+	}
+	if b.status != 0 {
+		b.w.WriteHeader(b.status)
+	}
+	if b.body.Len() == 0 {
+		return nil
+	}
+	_, err := b.w.Write(b.body.Bytes())
+	return err
+}
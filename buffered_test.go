@@ -0,0 +1,66 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestBufferedResponseWriter(t *testing.T) {
+	t.Run("replaces partial output with a clean error response", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := errs.NewBufferedResponseWriter(rec)
+		r := httptest.NewRequest(http.MethodGet, "/items", nil)
+
+		w.Header().Set("X-Total-Count", "42")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"items":[{"id":1},`))
+
+		err := errs.Mark(errors.New("cursor closed"), errs.ErrNotFound)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		if rec.Body.Len() != 0 {
+			t.Fatalf("response written before Commit: %s", rec.Body.String())
+		}
+		if err := w.Commit(); err != nil {
+			t.Fatalf("Commit() = %v", err)
+		}
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+		}
+		if want := `{"error":"The requested resource was not found"}`; rec.Body.String() != want {
+			t.Errorf("body = %s, want %s", rec.Body.String(), want)
+		}
+		if got := rec.Header().Get("X-Total-Count"); got != "" {
+			t.Errorf("X-Total-Count = %q, want discarded", got)
+		}
+	})
+
+	t.Run("commits successful responses unchanged", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := errs.NewBufferedResponseWriter(rec)
+
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("queued"))
+		if err := w.Commit(); err != nil {
+			t.Fatalf("Commit() = %v", err)
+		}
+		_, _ = w.Write([]byte(" 1 job"))
+
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusAccepted)
+		}
+		if rec.Body.String() != "queued 1 job" {
+			t.Errorf("body = %q, want %q", rec.Body.String(), "queued 1 job")
+		}
+		if got := rec.Header().Get("Content-Type"); got != "text/plain" {
+			t.Errorf("Content-Type = %q, want text/plain", got)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package errs
+
+import (
+	"errors"
+	"sync"
+)
+
+type canonicalMessage struct {
+	sentinel error
+	message  string
+}
+
+var (
+	canonicalMu sync.RWMutex
+	// canonicalMessages are matched in order for errors that GetHTTPCode
+	// does not map.
+	canonicalMessages = []canonicalMessage{
+		{ErrInternal, "An internal error occurred"},
+		{ErrNotImplemented, "This operation is not implemented"},
+		{ErrDeadlineExceeded, "The request timed out"},
+		{ErrRemoteServiceErr, "An upstream service failed"},
+		{ErrRateLimited, "Too many requests, please try again later"},
+		{ErrInvalidArgument, "The request contains an invalid argument"},
+		{ErrMissingArgument, "The request is missing a required argument"},
+		{ErrOutOfRange, "A value in the request is out of range"},
+		{ErrPermissionDenied, "You do not have permission to perform this action"},
+		{ErrUnauthorized, "Authentication is required"},
+		{ErrExists, "The resource already exists"},
+		{ErrOutdated, "The resource was modified, retry with the latest version"},
+		{ErrNotFound, "The requested resource was not found"},
+	}
+)
+
+type sentinelName struct {
+	sentinel error
+	name     string
+}
+
+// canonicalNames are guarded by canonicalMu.
+var canonicalNames = []sentinelName{
+	{ErrInternal, "INTERNAL"},
+	{ErrNotImplemented, "NOT_IMPLEMENTED"},
+	{ErrDeadlineExceeded, "DEADLINE_EXCEEDED"},
+	{ErrRemoteServiceErr, "REMOTE_SERVICE_ERROR"},
+	{ErrRateLimited, "RATE_LIMITED"},
+	{ErrInvalidArgument, "INVALID_ARGUMENT"},
+	{ErrMissingArgument, "MISSING_ARGUMENT"},
+	{ErrOutOfRange, "OUT_OF_RANGE"},
+	{ErrPermissionDenied, "PERMISSION_DENIED"},
+	{ErrUnauthorized, "UNAUTHORIZED"},
+	{ErrExists, "ALREADY_EXISTS"},
+	{ErrOutdated, "OUTDATED"},
+	{ErrNotFound, "NOT_FOUND"},
+}
+
+// Sentinels returns the sentinels of HTTPCodeMappings in precedence order.
+func Sentinels() []error {
+	sentinels := make([]error, 0, len(HTTPCodeMappings))
+	for _, m := range HTTPCodeMappings {
+		sentinels = appendMarker(sentinels, m.Sentinel)
+	}
+	return sentinels
+}
+
+// CanonicalName returns a stable upper snake case name, such as "NOT_FOUND",
+// for the sentinel that determines err's HTTP status.
+// Returns an empty string if err matches no named sentinel.
+func CanonicalName(err error) string {
+	sentinel := canonicalSentinel(err)
+	if sentinel == nil {
+		return ""
+	}
+
+	canonicalMu.RLock()
+	defer canonicalMu.RUnlock()
+
+	for _, n := range canonicalNames {
+		if errors.Is(sentinel, n.sentinel) {
+			return n.name
+		}
+	}
+	return ""
+}
+
+// RegisterCanonicalMessage sets the default user-facing message for errors
+// matching sentinel. It replaces the message of an already known sentinel;
+// new sentinels are matched after all previously registered ones.
+func RegisterCanonicalMessage(sentinel error, msg string) {
+	canonicalMu.Lock()
+	defer canonicalMu.Unlock()
+
+	for i := range canonicalMessages {
+		if canonicalMessages[i].sentinel == sentinel {
+			canonicalMessages[i].message = msg
+			return
+		}
+	}
+	canonicalMessages = append(canonicalMessages, canonicalMessage{sentinel, msg})
+}
+
+// RegisterSentinel integrates a domain sentinel, such as ErrQuotaExceeded,
+// in one call: errors matching it map to status, report code and get
+// safeMessage as their default user-facing message. Empty code or safeMessage
+// leave the respective registry untouched.
+// The status mapping is added to the end of HTTPCodeMappings, or replaces the
+// sentinel's existing entry, so like HTTPCodeMappings it must be registered
+// during initialization.
+func RegisterSentinel(sentinel error, status int, code, safeMessage string) {
+	registered := false
+	for i := range HTTPCodeMappings {
+		if HTTPCodeMappings[i].Sentinel == sentinel {
+			HTTPCodeMappings[i].Status = status
+			registered = true
+			break
+		}
+	}
+	if !registered {
+		HTTPCodeMappings = append(HTTPCodeMappings, HTTPCodeMapping{sentinel, status})
+	}
+	if code != "" {
+		RegisterCode(sentinel, code)
+	}
+	if safeMessage != "" {
+		RegisterCanonicalMessage(sentinel, safeMessage)
+	}
+}
+
+// getCanonicalMessage returns the registered message for the sentinel
+// that determines err's HTTP status. Only errors whose status no sentinel
+// determines get the message of the first registered sentinel they match,
+// so the message never describes another status than the response's.
+func getCanonicalMessage(err error) (string, bool) {
+	canonicalMu.RLock()
+	defer canonicalMu.RUnlock()
+
+	if m, ok := matchHTTPCodeMapping(err); ok {
+		for _, c := range canonicalMessages {
+			if errors.Is(m.Sentinel, c.sentinel) {
+				return c.message, true
+			}
+		}
+		return "", false
+	}
+	for _, c := range canonicalMessages {
+		if errors.Is(err, c.sentinel) {
+			return c.message, true
+		}
+	}
+	return "", false
+}
+
+// canonicalSentinel returns the sentinel that determines err's HTTP status,
+// or else the first sentinel with a registered message err matches.
+// Returns nil if err matches none.
+func canonicalSentinel(err error) error {
+	if m, ok := matchHTTPCodeMapping(err); ok {
+		return m.Sentinel
+	}
+
+	canonicalMu.RLock()
+	defer canonicalMu.RUnlock()
+
+	for _, c := range canonicalMessages {
+		if errors.Is(err, c.sentinel) {
+			return c.sentinel
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package errs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestSentinels(t *testing.T) {
+	// Statuses intentionally shared by several sentinels.
+	shared := map[int]bool{
+		http.StatusBadRequest: true,
+		http.StatusConflict:   true,
+	}
+
+	names := make(map[string]error)
+	statuses := make(map[int]error)
+	for _, sentinel := range errs.Sentinels() {
+		name := errs.CanonicalName(sentinel)
+		if name == "" {
+			t.Errorf("CanonicalName(%v) is empty", sentinel)
+		}
+		if prev, ok := names[name]; ok {
+			t.Errorf("CanonicalName(%v) = %q, already used by %v", sentinel, name, prev)
+		}
+		names[name] = sentinel
+
+		status := errs.GetHTTPCode(sentinel)
+		if prev, ok := statuses[status]; ok && !shared[status] {
+			t.Errorf("GetHTTPCode(%v) = %d, already used by %v", sentinel, status, prev)
+		}
+		statuses[status] = sentinel
+	}
+
+	if got := errs.Sentinels()[0]; got != errs.ErrInternal {
+		t.Errorf("Sentinels()[0] = %v, want ErrInternal first", got)
+	}
+}
+
+func TestCanonicalName(t *testing.T) {
+	err := errs.Wrap(errs.Mark(errors.New("no row"), errs.ErrNotFound), "load user")
+	if got := errs.CanonicalName(err); got != "NOT_FOUND" {
+		t.Errorf("CanonicalName() = %q, want %q", got, "NOT_FOUND")
+	}
+	if got := errs.CanonicalName(errors.New("boom")); got != "" {
+		t.Errorf("CanonicalName(foreign) = %q, want empty", got)
+	}
+}
+
+func TestRegisterSentinel(t *testing.T) {
+	errs.KeepRegistries(t)
+
+	errQuotaExceeded := errors.New("quota exceeded")
+	errs.RegisterSentinel(errQuotaExceeded, http.StatusPaymentRequired, "QUOTA_EXCEEDED", "Your plan quota is exhausted")
+
+	err := errs.Wrap(errs.Mark(errors.New("tenant 7 used 1001/1000 calls"), errQuotaExceeded), "create call")
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/calls", nil)
+	errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+		LogOptions:       []errs.LogErrOption{discard},
+		IncludeErrorCode: true,
+	})
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusPaymentRequired)
+	}
+	var body errs.ErrorHTTPResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != "QUOTA_EXCEEDED" {
+		t.Errorf("code = %q, want %q", body.Code, "QUOTA_EXCEEDED")
+	}
+	if body.Error != "Your plan quota is exhausted" {
+		t.Errorf("error = %q, want %q", body.Error, "Your plan quota is exhausted")
+	}
+}
@@ -0,0 +1,46 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Exit codes returned by HandleCLI.
+const (
+	ExitOK          = 0
+	ExitClientError = 1
+	ExitServerError = 2
+)
+
+// HandleCLI logs err with DefaultLogErrOptions, prints its user-facing
+// message to w and returns the exit code for the process: ExitOK for nil,
+// ExitClientError for errors mapping to 4xx and ExitServerError otherwise.
+// An explicit Error.ExitCode, set with WithExitCode, takes precedence.
+// The message is EffectiveSafeMessage, or the full internal message in DevMode.
+func HandleCLI(err error, w io.Writer) (exitCode int) {
+	if err == nil {
+		return ExitOK
+	}
+
+	status := GetHTTPCode(err)
+	config := DefaultLogErrOptions
+	config.LogLevel = HTTPGetLogLevel(status)
+	logErr(context.Background(), err, config)
+
+	msg := EffectiveSafeMessage(err)
+	if DevMode {
+		msg = err.Error()
+	}
+	fmt.Fprintln(w, msg)
+
+	var e *Error
+	if errors.As(err, &e) && e.ExitCode != 0 {
+		return e.ExitCode
+	}
+	if status < 500 {
+		return ExitClientError
+	}
+	return ExitServerError
+}
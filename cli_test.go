@@ -0,0 +1,80 @@
+package errs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestHandleCLI(t *testing.T) {
+	orig := errs.DefaultLogErrOptions
+	t.Cleanup(func() { errs.DefaultLogErrOptions = orig })
+	errs.DefaultLogErrOptions.Logger = slog.New(slog.DiscardHandler)
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantOut  string
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			wantCode: errs.ExitOK,
+			wantOut:  "",
+		},
+		{
+			name:     "not found",
+			err:      errs.Mark(errors.New("no row for id 7"), errs.ErrNotFound),
+			wantCode: errs.ExitClientError,
+			wantOut:  "The requested resource was not found\n",
+		},
+		{
+			name:     "internal",
+			err:      errors.New("db connection reset"),
+			wantCode: errs.ExitServerError,
+			wantOut:  "Internal Server Error\n",
+		},
+		{
+			name:     "explicit exit code",
+			err:      errs.New("lock held", errs.WithExitCode(42), errs.WithMarkers(errs.ErrExists)),
+			wantCode: 42,
+			wantOut:  "The resource already exists\n",
+		},
+		{
+			name:     "explicit exit code through wrapping",
+			err:      fmt.Errorf("acquire: %w", errs.New("lock held", errs.WithExitCode(42))),
+			wantCode: 42,
+			wantOut:  "Internal Server Error\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if got := errs.HandleCLI(tt.err, &out); got != tt.wantCode {
+				t.Errorf("HandleCLI() = %d, want %d", got, tt.wantCode)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+
+	t.Run("prints internal message in dev", func(t *testing.T) {
+		origDev := errs.DevMode
+		t.Cleanup(func() { errs.DevMode = origDev })
+		errs.DevMode = true
+
+		var out bytes.Buffer
+		errs.HandleCLI(errors.New("db connection reset"), &out)
+
+		if want := "db connection reset\n"; out.String() != want {
+			t.Errorf("output = %q, want %q", out.String(), want)
+		}
+	})
+}
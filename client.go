@@ -0,0 +1,52 @@
+package errs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBodyBytes limits how much of an error response FromHTTPResponse reads.
+const maxErrorBodyBytes = 1 << 20
+
+// FromHTTPResponse converts a non-2xx response, such as one written by
+// HandleHTTPErr, into an *Error marked with the sentinel mapped to its
+// status (see MarkFromStatus). The parsed "error" becomes SafeMessage;
+// code, hints, details and error_info are kept, and the status is
+// recorded in LogDetails. Bodies that are not an ErrorHTTPResponse fall
+// back to the status text.
+// The body is read but not closed. Returns nil for 2xx responses.
+func FromHTTPResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var body ErrorHTTPResponse
+	if resp.Body != nil {
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		if err == nil {
+			_ = json.Unmarshal(raw, &body)
+		}
+	}
+	msg := body.Error
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+
+	e := &Error{
+		Internal:    fmt.Errorf("http %d: %s", resp.StatusCode, msg),
+		SafeMessage: msg,
+		UserDetails: body.Details,
+		Hints:       body.Hints,
+		Code:        body.Code,
+		LogDetails:  []any{"status", resp.StatusCode},
+		Markers:     []error{sentinelForStatus(resp.StatusCode)},
+		CreatedAt:   Clock(),
+	}
+	if body.ErrorInfo != nil {
+		e.Reason = body.ErrorInfo.Reason
+		e.Domain = body.ErrorInfo.Domain
+	}
+	return e
+}
@@ -0,0 +1,68 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestFromHTTPResponse(t *testing.T) {
+	t.Run("converts a recorded 404 response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		errs.HandleHTTPErr(context.Background(), w, r,
+			errs.Mark(errors.New("no row"), errs.ErrNotFound, errs.WithReason("USER_MISSING"), errs.WithHint("check the id")),
+			&errs.HandleHTTPErrOpts{LogOptions: []errs.LogErrOption{discard}, IncludeErrorCode: true},
+		)
+
+		err := errs.FromHTTPResponse(w.Result())
+
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Errorf("errors.Is(err, ErrNotFound) = false, want true")
+		}
+		var e *errs.Error
+		if !errors.As(err, &e) {
+			t.Fatalf("FromHTTPResponse() = %T, want *errs.Error", err)
+		}
+		if e.SafeMessage != "The requested resource was not found" {
+			t.Errorf("SafeMessage = %q, want canonical not found message", e.SafeMessage)
+		}
+		if e.Code != "NOT_FOUND" || e.Reason != "USER_MISSING" {
+			t.Errorf("Code, Reason = %q, %q, want NOT_FOUND, USER_MISSING", e.Code, e.Reason)
+		}
+		if len(e.Hints) != 1 || e.Hints[0] != "check the id" {
+			t.Errorf("Hints = %v, want [check the id]", e.Hints)
+		}
+		if got := errs.DetailsMap(err)["status"]; got != http.StatusNotFound {
+			t.Errorf("status detail = %v, want %d", got, http.StatusNotFound)
+		}
+	})
+
+	t.Run("falls back to status text for foreign bodies", func(t *testing.T) {
+		resp := &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       io.NopCloser(strings.NewReader("<html>bad gateway</html>")),
+		}
+
+		err := errs.FromHTTPResponse(resp)
+
+		if got := errs.GetHTTPCode(err); got != http.StatusBadGateway {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusBadGateway)
+		}
+		if got := errs.EffectiveSafeMessage(err); got != "Bad Gateway" {
+			t.Errorf("EffectiveSafeMessage() = %q, want %q", got, "Bad Gateway")
+		}
+	})
+
+	t.Run("returns nil for 2xx", func(t *testing.T) {
+		if err := errs.FromHTTPResponse(&http.Response{StatusCode: http.StatusNoContent}); err != nil {
+			t.Errorf("FromHTTPResponse(204) = %v, want nil", err)
+		}
+	})
+}
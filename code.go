@@ -0,0 +1,92 @@
+package errs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// registeredCodes are guarded by canonicalMu.
+var registeredCodes []sentinelName
+
+// RegisterCode sets the code reported by Code for errors matching sentinel,
+// taking precedence over its CanonicalName. It replaces the code of an
+// already registered sentinel.
+func RegisterCode(sentinel error, code string) {
+	canonicalMu.Lock()
+	defer canonicalMu.Unlock()
+
+	for i := range registeredCodes {
+		if registeredCodes[i].sentinel == sentinel {
+			registeredCodes[i].name = code
+			return
+		}
+	}
+	registeredCodes = append(registeredCodes, sentinelName{sentinel, code})
+}
+
+// Code returns the machine-readable code of err. It is, in order:
+// the Code of the nearest *Error if set, the code registered with
+// RegisterCode for the sentinel err matches, the CanonicalName of err,
+// and finally CodeFromStatus for err's HTTP status.
+// Returns an empty string if err is nil.
+func Code(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var e *Error
+	if errors.As(err, &e) && e.Code != "" {
+		return e.Code
+	}
+	if code, ok := registeredCode(err); ok {
+		return code
+	}
+	if name := CanonicalName(err); name != "" {
+		return name
+	}
+	return CodeFromStatus(GetHTTPCode(err))
+}
+
+// registeredCode returns the code registered for the sentinel that
+// determines err's HTTP status, or else for the first registered sentinel
+// err matches.
+func registeredCode(err error) (string, bool) {
+	canonicalMu.RLock()
+	defer canonicalMu.RUnlock()
+
+	if m, ok := matchHTTPCodeMapping(err); ok {
+		for _, c := range registeredCodes {
+			if errors.Is(m.Sentinel, c.sentinel) {
+				return c.name, true
+			}
+		}
+	}
+	for _, c := range registeredCodes {
+		if errors.Is(err, c.sentinel) {
+			return c.name, true
+		}
+	}
+	return "", false
+}
+
+// CodeFromStatus derives a code from an HTTP status text in upper snake case,
+// e.g. "INTERNAL_SERVER_ERROR" for 500. Unknown statuses give "HTTP_<status>".
+func CodeFromStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "HTTP_" + strconv.Itoa(status)
+	}
+
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		if b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteString(strings.ToUpper(word))
+	}
+	return b.String()
+}
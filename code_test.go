@@ -0,0 +1,80 @@
+package errs_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestCode(t *testing.T) {
+	errs.KeepRegistries(t)
+	errQuota := errors.New("quota exceeded")
+	errs.RegisterCode(errQuota, "QUOTA_EXCEEDED")
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "explicit code",
+			err:  errs.Mark(errors.New("no row"), errs.ErrNotFound, errs.WithCode("USER_NOT_FOUND")),
+			want: "USER_NOT_FOUND",
+		},
+		{
+			name: "explicit code survives wrapping",
+			err:  errs.Wrap(errs.New("no row", errs.WithCode("USER_NOT_FOUND")), "get user"),
+			want: "USER_NOT_FOUND",
+		},
+		{
+			name: "known sentinel",
+			err:  errs.Mark(errors.New("no row"), errs.ErrNotFound),
+			want: "NOT_FOUND",
+		},
+		{
+			name: "registered code",
+			err:  errs.Mark(errors.New("tenant 7 over quota"), errQuota),
+			want: "QUOTA_EXCEEDED",
+		},
+		{
+			name: "foreign error",
+			err:  errors.New("db connection reset"),
+			want: "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.Code(tt.err); got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeFromStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{http.StatusInternalServerError, "INTERNAL_SERVER_ERROR"},
+		{http.StatusTeapot, "I_M_A_TEAPOT"},
+		{http.StatusNotFound, "NOT_FOUND"},
+		{799, "HTTP_799"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := errs.CodeFromStatus(tt.status); got != tt.want {
+				t.Errorf("CodeFromStatus(%d) = %q, want %q", tt.status, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package errs
+
+import "errors"
+
+// Equal reports whether a and b are semantically equal: same message,
+// Domain, Reason and Code, and the same set of markers regardless of order.
+// Stacks, timestamps and details are ignored. It is intended for tests.
+func Equal(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Error() != b.Error() {
+		return false
+	}
+
+	var ea, eb *Error
+	okA, okB := errors.As(a, &ea), errors.As(b, &eb)
+	if okA != okB {
+		return false
+	}
+	if !okA {
+		return true
+	}
+
+	return ea.Domain == eb.Domain &&
+		ea.Reason == eb.Reason &&
+		ea.Code == eb.Code &&
+		sameMarkers(ea.Markers, eb.Markers)
+}
+
+// sameMarkers reports whether every marker of a matches one of b
+// and vice versa.
+func sameMarkers(a, b []error) bool {
+	for _, m := range a {
+		if !IsAny(m, b...) {
+			return false
+		}
+	}
+	for _, m := range b {
+		if !IsAny(m, a...) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizedError is the canonical form of an error returned by
+// NormalizeForComparison.
+type NormalizedError struct {
+	Message     string         `json:"message"`
+	Domain      string         `json:"domain,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+	Code        string         `json:"code,omitempty"`
+	Markers     []string       `json:"markers,omitempty"`
+	Details     map[string]any `json:"details,omitempty"`
+	UserDetails any            `json:"user_details,omitempty"`
+	Hints       []string       `json:"hints,omitempty"`
+}
+
+// NormalizeForComparison returns a canonical form of err for golden tests:
+// markers become their names in sorted order and details a map, as reported
+// by DetailsMap, which reflect.DeepEqual compares and encoding/json marshals
+// independently of order. Stacks and timestamps are dropped.
+// It is intended for tests. Returns nil if err is nil.
+func NormalizeForComparison(err error) *NormalizedError {
+	if err == nil {
+		return nil
+	}
+
+	n := &NormalizedError{Message: err.Error()}
+	var e *Error
+	if errors.As(err, &e) {
+		n.Domain = e.Domain
+		n.Reason = e.Reason
+		n.Code = e.Code
+		n.UserDetails = e.userDetails()
+	}
+	for _, m := range sortedMarkers(Markers(err)) {
+		n.Markers = append(n.Markers, markerName(m))
+	}
+	if details := DetailsMap(err); len(details) > 0 {
+		n.Details = details
+	}
+	n.Hints = GetAllHints(err)
+	return n
+}
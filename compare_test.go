@@ -0,0 +1,93 @@
+package errs_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestEqual(t *testing.T) {
+	t.Run("independently built equivalent errors are equal", func(t *testing.T) {
+		a := errs.Mark(
+			errs.Mark(errs.Newf("user %d not found", 7, errs.WithStack()), errs.ErrNotFound),
+			errs.ErrPermissionDenied,
+			func(e *errs.Error) { e.Domain = "users" },
+		)
+		b := errs.New("user 7 not found",
+			errs.WithMarkers(errs.ErrPermissionDenied, errs.ErrNotFound),
+			func(e *errs.Error) {
+				e.Domain = "users"
+				e.LogDetails = []any{"attempt", 2}
+			},
+		)
+
+		if !errs.Equal(a, b) {
+			t.Errorf("Equal(%+v, %+v) = false, want true", a, b)
+		}
+	})
+
+	t.Run("different markers are not equal", func(t *testing.T) {
+		a := errs.Mark(errors.New("boom"), errs.ErrNotFound)
+		b := errs.Mark(errors.New("boom"), errs.ErrExists)
+
+		if errs.Equal(a, b) {
+			t.Error("Equal() = true, want false")
+		}
+	})
+
+	t.Run("different domains are not equal", func(t *testing.T) {
+		a := errs.New("boom", func(e *errs.Error) { e.Domain = "a" })
+		b := errs.New("boom", func(e *errs.Error) { e.Domain = "b" })
+
+		if errs.Equal(a, b) {
+			t.Error("Equal() = true, want false")
+		}
+	})
+
+	t.Run("handles nil", func(t *testing.T) {
+		if !errs.Equal(nil, nil) {
+			t.Error("Equal(nil, nil) = false, want true")
+		}
+		if errs.Equal(errs.New("boom"), nil) {
+			t.Error("Equal(err, nil) = true, want false")
+		}
+	})
+}
+
+func TestNormalizeForComparison(t *testing.T) {
+	t.Run("differently ordered errors normalize identically", func(t *testing.T) {
+		a := errs.New("user 7 not found",
+			errs.WithMarkers(errs.ErrNotFound, errs.ErrPermissionDenied),
+			func(e *errs.Error) { e.LogDetails = []any{"user", 7, "attempt", 2} },
+		)
+		b := errs.New("user 7 not found",
+			errs.WithMarkers(errs.ErrPermissionDenied, errs.ErrNotFound),
+			errs.WithStack(),
+			func(e *errs.Error) { e.LogDetails = []any{"attempt", 2, "user", 7} },
+		)
+
+		na, nb := errs.NormalizeForComparison(a), errs.NormalizeForComparison(b)
+		if !reflect.DeepEqual(na, nb) {
+			t.Errorf("normalized forms differ:\n%+v\n%+v", na, nb)
+		}
+
+		ja, _ := json.Marshal(na)
+		jb, _ := json.Marshal(nb)
+		if string(ja) != string(jb) {
+			t.Errorf("JSON differs:\n%s\n%s", ja, jb)
+		}
+		want := `{"message":"user 7 not found","markers":["NOT_FOUND","PERMISSION_DENIED"],"details":{"attempt":2,"user":7}}`
+		if string(ja) != want {
+			t.Errorf("JSON = %s, want %s", ja, want)
+		}
+	})
+
+	t.Run("returns nil for nil error", func(t *testing.T) {
+		if got := errs.NormalizeForComparison(nil); got != nil {
+			t.Errorf("NormalizeForComparison(nil) = %+v, want nil", got)
+		}
+	})
+}
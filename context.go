@@ -0,0 +1,59 @@
+package errs
+
+import (
+	"context"
+	"sync"
+)
+
+type errorSlotKey struct{}
+
+// errorSlot holds the error stored on a context. It is shared by the
+// contexts derived from the one WithError returned, so HandleHTTPErr can
+// fill it for middleware further up the chain.
+type errorSlot struct {
+	mu  sync.Mutex
+	err error
+	// handled records that HandleHTTPErr already wrote the response.
+	handled bool
+}
+
+// WithError returns a copy of ctx carrying err, retrieved by ErrorFromContext.
+// Middleware can pass a nil err to reserve a slot that HandleHTTPErr fills
+// with the error it handled when HandleHTTPErrOpts.StoreInContext is set:
+//
+//	ctx := errs.WithError(r.Context(), nil)
+//	next.ServeHTTP(w, r.WithContext(ctx))
+//	accessLog(r, errs.ErrorFromContext(ctx))
+func WithError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorSlotKey{}, &errorSlot{err: err})
+}
+
+// ErrorFromContext returns the error stored on ctx by WithError or
+// HandleHTTPErr, or nil if there is none.
+func ErrorFromContext(ctx context.Context) error {
+	slot, ok := ctx.Value(errorSlotKey{}).(*errorSlot)
+	if !ok {
+		return nil
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	return slot.err
+}
+
+// errorSlotOf returns the slot of ctx, or nil if it has none.
+func errorSlotOf(ctx context.Context) *errorSlot {
+	slot, _ := ctx.Value(errorSlotKey{}).(*errorSlot)
+	return slot
+}
+
+// storeError records err in the slot of ctx, reporting whether ctx has one.
+func storeError(ctx context.Context, err error) bool {
+	slot := errorSlotOf(ctx)
+	if slot == nil {
+		return false
+	}
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	slot.err = err
+	return true
+}
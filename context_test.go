@@ -0,0 +1,56 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestErrorFromContext(t *testing.T) {
+	t.Run("returns the stored error", func(t *testing.T) {
+		err := errors.New("boom")
+		ctx := errs.WithError(context.Background(), err)
+
+		if got := errs.ErrorFromContext(ctx); got != err {
+			t.Errorf("ErrorFromContext() = %v, want %v", got, err)
+		}
+	})
+
+	t.Run("returns nil without an error", func(t *testing.T) {
+		if got := errs.ErrorFromContext(context.Background()); got != nil {
+			t.Errorf("ErrorFromContext() = %v, want nil", got)
+		}
+	})
+
+	t.Run("is filled by HandleHTTPErr", func(t *testing.T) {
+		err := errs.Mark(errors.New("no row"), errs.ErrNotFound)
+
+		// Reserved by access log middleware; the handler sees the request context.
+		ctx := errs.WithError(context.Background(), nil)
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions:     []errs.LogErrOption{discard},
+			StoreInContext: true,
+		})
+
+		if got := errs.ErrorFromContext(ctx); got != err {
+			t.Errorf("ErrorFromContext() = %v, want %v", got, err)
+		}
+	})
+
+	t.Run("is not filled without StoreInContext", func(t *testing.T) {
+		ctx := errs.WithError(context.Background(), nil)
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		errs.HandleHTTP(ctx, w, r, errors.New("boom"), discard)
+
+		if got := errs.ErrorFromContext(ctx); got != nil {
+			t.Errorf("ErrorFromContext() = %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package errs
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+type dbMapping struct {
+	src    error
+	marker error
+}
+
+var (
+	dbMu       sync.RWMutex
+	dbMappings = []dbMapping{
+		{sql.ErrNoRows, ErrNotFound},
+	}
+)
+
+// RegisterDBError makes FromDBErr mark errors matching src with marker,
+// e.g. a driver's unique violation sentinel with ErrExists.
+// Mappings are tried in registration order, after sql.ErrNoRows.
+func RegisterDBError(src, marker error) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	dbMappings = append(dbMappings, dbMapping{src, marker})
+}
+
+// FromDBErr translates a database error into the package taxonomy:
+// sql.ErrNoRows is marked ErrNotFound, and errors registered with
+// RegisterDBError get their marker. The original error still matches
+// errors.Is. Unknown errors are returned unchanged. Returns nil if err is nil.
+func FromDBErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+
+	for _, m := range dbMappings {
+		if errors.Is(err, m.src) {
+			return Mark(err, m.marker)
+		}
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package errs_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestFromDBErr(t *testing.T) {
+	t.Run("maps sql.ErrNoRows to not found", func(t *testing.T) {
+		err := errs.FromDBErr(fmt.Errorf("select user: %w", sql.ErrNoRows))
+
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Error("errors.Is(err, ErrNotFound) = false, want true")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Error("errors.Is(err, sql.ErrNoRows) = false, want true")
+		}
+		if got := errs.GetHTTPCode(err); got != http.StatusNotFound {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusNotFound)
+		}
+	})
+
+	t.Run("maps registered driver errors", func(t *testing.T) {
+		errs.KeepRegistries(t)
+		errUniqueViolation := errors.New("duplicate key value violates unique constraint")
+		errs.RegisterDBError(errUniqueViolation, errs.ErrExists)
+
+		err := errs.FromDBErr(errUniqueViolation)
+		if got := errs.GetHTTPCode(err); got != http.StatusConflict {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusConflict)
+		}
+	})
+
+	t.Run("returns unknown errors unchanged", func(t *testing.T) {
+		base := errors.New("connection reset")
+		if got := errs.FromDBErr(base); got != base {
+			t.Errorf("FromDBErr() = %v, want original error", got)
+		}
+		if got := errs.FromDBErr(nil); got != nil {
+			t.Errorf("FromDBErr(nil) = %v, want nil", got)
+		}
+	})
+}
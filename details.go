@@ -0,0 +1,104 @@
+package errs
+
+import "log/slog"
+
+// SafeDetailer is implemented by foreign errors that carry details safe
+// for logging, such as errors built with github.com/cockroachdb/errors.
+type SafeDetailer interface {
+	SafeDetails() []string
+}
+
+// GetAllDetails collects log details from every layer of err's chain,
+// ordered from the root cause outwards. Details Wrap copied from an inner
+// *Error are reported once. Foreign errors implementing SafeDetailer
+// contribute a "safe_details" pair.
+func GetAllDetails(err error) []any {
+	return collectDetails(err, make([]any, 0))
+}
+
+func collectDetails(err error, details []any) []any {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			details = collectDetails(inner, details)
+		}
+	case interface{ Unwrap() error }:
+		if inner := u.Unwrap(); inner != nil {
+			details = collectDetails(inner, details)
+		}
+	}
+
+	switch e := err.(type) {
+	case *Error:
+		own := e.LogDetails
+		if e.inheritedDetails <= len(own) {
+			own = own[e.inheritedDetails:]
+		}
+		details = append(details, own...)
+	case SafeDetailer:
+		if safe := e.SafeDetails(); len(safe) > 0 {
+			details = append(details, "safe_details", safe)
+		}
+	}
+	return details
+}
+
+// DetailsMap interprets the details reported by GetAllDetails as alternating
+// key/value pairs, the way slog does, and returns them as a map. slog.Attr
+// entries are taken as single pairs. Malformed entries, such as a non-string
+// key or a trailing key without a value, are skipped. When a key repeats,
+// the outermost layer wins.
+// Returns nil if err is nil.
+func DetailsMap(err error) map[string]any {
+	if err == nil {
+		return nil
+	}
+
+	details := GetAllDetails(err)
+	m := make(map[string]any, len(details)/2)
+	for i := 0; i < len(details); i++ {
+		switch key := details[i].(type) {
+		case slog.Attr:
+			m[key.Key] = key.Value.Any()
+		case string:
+			if i+1 < len(details) {
+				m[key] = details[i+1]
+				i++
+			}
+		}
+	}
+	return m
+}
+
+// CopyDetails returns a copy of dst with the details of src, as reported
+// by GetAllDetails, appended to its LogDetails.
+// Returns nil if dst is nil.
+func CopyDetails(dst, src error) error {
+	if dst == nil {
+		return nil
+	}
+	e := clone(dst)
+	WithDetailsFrom(src)(e)
+	return e
+}
+
+// GetAllHints collects the hints of every *Error in err's chain,
+// dropping duplicates while keeping the order they were first seen in.
+func GetAllHints(err error) []string {
+	var hints []string
+	seen := make(map[string]struct{})
+	Walk(err, func(err error) bool {
+		e, ok := err.(*Error)
+		if !ok {
+			return true
+		}
+		for _, hint := range e.Hints {
+			if _, ok := seen[hint]; !ok {
+				seen[hint] = struct{}{}
+				hints = append(hints, hint)
+			}
+		}
+		return true
+	})
+	return hints
+}
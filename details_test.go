@@ -0,0 +1,154 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+// crdbErr mimics an error from github.com/cockroachdb/errors.
+type crdbErr struct {
+	msg     string
+	details []string
+}
+
+func (c *crdbErr) Error() string         { return c.msg }
+func (c *crdbErr) SafeDetails() []string { return c.details }
+
+func TestGetAllDetails(t *testing.T) {
+	t.Run("collects details across foreign wrapping without duplicates", func(t *testing.T) {
+		inner := errs.New("query failed", func(e *errs.Error) {
+			e.LogDetails = []any{"table", "users"}
+		})
+		inner = errs.Wrap(inner, "load user", func(e *errs.Error) {
+			e.LogDetails = append(e.LogDetails, "user_id", 7)
+		})
+		err := fmt.Errorf("handler: %w", inner)
+
+		want := []any{"table", "users", "user_id", 7}
+		if got := errs.GetAllDetails(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAllDetails() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("includes safe details of foreign errors", func(t *testing.T) {
+		foreign := &crdbErr{msg: "txn aborted", details: []string{"retry=3"}}
+
+		want := []any{"safe_details", []string{"retry=3"}}
+		if got := errs.GetAllDetails(foreign); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAllDetails() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestDetailsMap(t *testing.T) {
+	t.Run("merges details of every layer", func(t *testing.T) {
+		inner := errs.New("query failed", func(e *errs.Error) {
+			e.LogDetails = []any{"table", "users", "attempt", 1}
+		})
+		err := errs.Wrap(inner, "load user", func(e *errs.Error) {
+			e.LogDetails = append(e.LogDetails, slog.Int("user_id", 7), "attempt", 2)
+		})
+		err = fmt.Errorf("handler: %w", err)
+
+		want := map[string]any{"table": "users", "user_id": int64(7), "attempt": 2}
+		if got := errs.DetailsMap(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("DetailsMap() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("skips malformed entries", func(t *testing.T) {
+		err := errs.New("query failed", func(e *errs.Error) {
+			e.LogDetails = []any{42, "table", "users", "dangling"}
+		})
+
+		want := map[string]any{"table": "users"}
+		if got := errs.DetailsMap(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("DetailsMap() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("returns nil when err is nil", func(t *testing.T) {
+		if got := errs.DetailsMap(nil); got != nil {
+			t.Errorf("DetailsMap(nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestCopyDetails(t *testing.T) {
+	foreign := &crdbErr{msg: "txn aborted", details: []string{"retry=3", "node=2"}}
+	want := []any{"safe_details", []string{"retry=3", "node=2"}}
+
+	t.Run("WithDetailsFrom pulls details into a new error", func(t *testing.T) {
+		err := errs.New("could not save order", errs.WithDetailsFrom(foreign))
+
+		asErr := err.(*errs.Error)
+		if !reflect.DeepEqual(asErr.LogDetails, want) {
+			t.Errorf("LogDetails = %v, want %v", asErr.LogDetails, want)
+		}
+	})
+
+	t.Run("CopyDetails does not mutate dst", func(t *testing.T) {
+		dst := errs.New("could not save order")
+		copied := errs.CopyDetails(dst, foreign)
+
+		if got := copied.(*errs.Error).LogDetails; !reflect.DeepEqual(got, want) {
+			t.Errorf("LogDetails = %v, want %v", got, want)
+		}
+		if got := dst.(*errs.Error).LogDetails; len(got) != 0 {
+			t.Errorf("dst LogDetails mutated: %v", got)
+		}
+		if errors.Is(copied, foreign) {
+			t.Error("errors.Is(copied, foreign) = true, want false")
+		}
+	})
+
+	t.Run("returns nil when dst is nil", func(t *testing.T) {
+		if got := errs.CopyDetails(nil, foreign); got != nil {
+			t.Errorf("CopyDetails(nil, src) = %v, want nil", got)
+		}
+	})
+}
+
+func TestGetAllHints(t *testing.T) {
+	t.Run("dedupes hints added by several layers", func(t *testing.T) {
+		inner := errs.Mark(errors.New("upstream busy"), errs.ErrRateLimited, errs.WithHint("retry later"))
+		outer := errs.Wrap(fmt.Errorf("fetch: %w", inner), "sync",
+			errs.WithHint("retry later"),
+			errs.WithHint("reduce batch size"),
+		)
+
+		want := []string{"retry later", "reduce batch size"}
+		if got := errs.GetAllHints(outer); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAllHints() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("copies do not share hints", func(t *testing.T) {
+		base := errs.New("quota check failed",
+			errs.WithHint("h0"), errs.WithHint("h1"), errs.WithHint("h2"),
+		)
+		a := errs.Mark(base, errs.ErrNotFound, errs.WithHint("A"))
+		b := errs.Mark(base, errs.ErrNotFound, errs.WithHint("B"))
+
+		if got, want := a.(*errs.Error).Hints, []string{"h0", "h1", "h2", "A"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("a.Hints = %v, want %v", got, want)
+		}
+		if got, want := b.(*errs.Error).Hints, []string{"h0", "h1", "h2", "B"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("b.Hints = %v, want %v", got, want)
+		}
+		if got := len(base.(*errs.Error).Hints); got != 3 {
+			t.Errorf("len(base.Hints) = %d, want 3", got)
+		}
+	})
+
+	t.Run("returns nil without hints", func(t *testing.T) {
+		if got := errs.GetAllHints(errors.New("boom")); got != nil {
+			t.Errorf("GetAllHints() = %v, want nil", got)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package errs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Dump renders err as an indented tree for reading in a terminal: one node
+// per layer of the chain, from err down to the root cause, with the domain,
+// markers and own details of each *Error layer and the stack where it was
+// captured. It exposes internals and must not be sent to clients.
+// Returns an empty string if err is nil.
+func Dump(err error) string {
+	if err == nil {
+		return ""
+	}
+	var b strings.Builder
+	dump(&b, err, 0)
+	return b.String()
+}
+
+func dump(b *strings.Builder, err error, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(b, "%s- %s\n", indent, err.Error())
+
+	var inner []error
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		inner = u.Unwrap()
+	case interface{ Unwrap() error }:
+		if next := u.Unwrap(); next != nil {
+			inner = []error{next}
+		}
+	}
+
+	if e, ok := err.(*Error); ok {
+		if e.Domain != "" {
+			fmt.Fprintf(b, "%s  domain: %s\n", indent, e.Domain)
+		}
+		if len(e.Markers) > 0 {
+			names := make([]string, 0, len(e.Markers))
+			for _, m := range sortedMarkers(e.Markers) {
+				names = append(names, m.Error())
+			}
+			fmt.Fprintf(b, "%s  markers: %s\n", indent, strings.Join(names, ", "))
+		}
+		own := e.LogDetails
+		if e.inheritedDetails <= len(own) {
+			own = own[e.inheritedDetails:]
+		}
+		if len(own) > 0 {
+			fmt.Fprintf(b, "%s  details: %s\n", indent, formatDetails(own))
+		}
+		if len(e.Stack) > 0 && !sharesStack(e, inner) {
+			fmt.Fprintf(b, "%s  stack:\n", indent)
+			for _, line := range strings.Split(strings.TrimRight(string(e.Stack), "\n"), "\n") {
+				fmt.Fprintf(b, "%s    %s\n", indent, line)
+			}
+		}
+	}
+
+	for _, next := range inner {
+		dumpChild(b, err.Error(), next, depth+1)
+	}
+}
+
+// dumpChild dumps next below a parent with message parentMsg. Layers that
+// only repeat their parent's message, such as the fmt wrapper inside Wrap
+// or the error marked by Mark, are skipped in favor of their children.
+func dumpChild(b *strings.Builder, parentMsg string, next error, depth int) {
+	if _, ok := next.(*Error); ok || next.Error() != parentMsg {
+		dump(b, next, depth)
+		return
+	}
+	switch u := next.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			dumpChild(b, parentMsg, inner, depth)
+		}
+	case interface{ Unwrap() error }:
+		if inner := u.Unwrap(); inner != nil {
+			dumpChild(b, parentMsg, inner, depth)
+		}
+	}
+}
+
+// sharesStack reports whether a wrapped layer reports the same stack as e,
+// in which case it is printed there instead.
+func sharesStack(e *Error, inner []error) bool {
+	for _, next := range inner {
+		var ie *Error
+		if errors.As(next, &ie) && bytes.Equal(ie.Stack, e.Stack) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatDetails renders key/value details as "key=value" pairs.
+func formatDetails(details []any) string {
+	parts := make([]string, 0, len(details)/2+1)
+	for i := 0; i < len(details); i++ {
+		switch key := details[i].(type) {
+		case slog.Attr:
+			parts = append(parts, fmt.Sprintf("%s=%v", key.Key, key.Value))
+		case string:
+			if i+1 < len(details) {
+				parts = append(parts, fmt.Sprintf("%s=%v", key, redact(details[i+1])))
+				i++
+				continue
+			}
+			parts = append(parts, key)
+		default:
+			parts = append(parts, fmt.Sprint(key))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,58 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestDump(t *testing.T) {
+	t.Run("renders every layer as a tree", func(t *testing.T) {
+		root := errors.New("no row")
+		inner := errs.Mark(root, errs.ErrNotFound, func(e *errs.Error) {
+			e.Domain = "users"
+			e.LogDetails = []any{"table", "users"}
+		})
+		err := errs.Wrap(inner, "load user", func(e *errs.Error) {
+			e.LogDetails = append(e.LogDetails, "user_id", 7)
+		})
+		err = fmt.Errorf("handler: %w", err)
+
+		want := strings.Join([]string{
+			"- handler: load user: no row",
+			"  - load user: no row",
+			"    domain: users",
+			"    markers: not found",
+			"    details: user_id=7",
+			"    - no row",
+			"      domain: users",
+			"      markers: not found",
+			"      details: table=users",
+			"",
+		}, "\n")
+		if got := errs.Dump(err); got != want {
+			t.Errorf("Dump() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("prints the stack once where it was captured", func(t *testing.T) {
+		err := errs.Wrap(errs.New("boom", errs.WithStack()), "run")
+
+		dump := errs.Dump(err)
+		if got := strings.Count(dump, "stack:"); got != 1 {
+			t.Errorf("stack printed %d times, want 1:\n%s", got, dump)
+		}
+		if !strings.Contains(dump, "TestDump") {
+			t.Errorf("dump = %s, want stack frames", dump)
+		}
+	})
+
+	t.Run("returns empty string for nil", func(t *testing.T) {
+		if got := errs.Dump(nil); got != "" {
+			t.Errorf("Dump(nil) = %q, want empty", got)
+		}
+	})
+}
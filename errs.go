@@ -5,15 +5,30 @@
 package errs
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
+	ErrInternal         = errors.New("internal error")
 	ErrNotImplemented   = errors.New("not implemented")
 	ErrRemoteServiceErr = errors.New("remote service error")
 	ErrRateLimited      = errors.New("rate limited")
 
+	// ErrDeadlineExceeded is context.DeadlineExceeded,
+	// so both match the same errors.
+	ErrDeadlineExceeded = context.DeadlineExceeded
+
 	ErrInvalidArgument = errors.New("invalid argument")
 	ErrMissingArgument = errors.New("missing argument")
 	ErrOutOfRange      = errors.New("out of range")
@@ -26,11 +41,45 @@ var (
 	ErrOutdated = errors.New("outdated")
 )
 
+var defaultDomain atomic.Value // string
+
+// SetDefaultDomain sets the Domain given to errors built by New, Newf, Wrap
+// and Factory.Err without one, typically the service name, to correlate
+// logs across services. An empty name disables it.
+func SetDefaultDomain(name string) {
+	defaultDomain.Store(name)
+}
+
+// applyDefaultDomain sets e.Domain to the default domain if it is empty.
+func applyDefaultDomain(e *Error) {
+	if e.Domain != "" {
+		return
+	}
+	if name, ok := defaultDomain.Load().(string); ok {
+		e.Domain = name
+	}
+}
+
+// Clock returns the current time, recorded as Error.CreatedAt and used to
+// throttle logging. It can be replaced in tests.
+var Clock = time.Now
+
+// MaxWrapDepth, if positive, bounds the message of deep Wrap chains: past
+// that many layers Wrap keeps only its own context, a count of the collapsed
+// layers and the root message. The chain itself is kept, so errors.Is and
+// errors.As still reach every layer. Zero means no limit.
+var MaxWrapDepth = 0
+
 type Error struct {
 	// Internal is the underlying cause.
 	// By being an 'error' type, it allows for %w wrapping.
 	Internal error
 
+	// Cause records where the error originated, e.g. a failure reported by
+	// another goroutine, for logging only. Unlike Internal it is not part of
+	// the chain: Unwrap does not return it and errors.Is/As do not see it.
+	Cause error
+
 	// Whether or not show user external message if Message field is empty
 	ExposeInternal bool
 
@@ -43,11 +92,59 @@ type Error struct {
 	// UserDetails gets marshaled to the JSON response and sent to the user
 	UserDetails any
 
+	// Hints are user-facing suggestions on how to resolve the error,
+	// sent in the "hints" field of the response.
+	Hints []string
+
 	// TraceID or Domain can be added here for "Marking" where the error originated.
 	Domain string
 
-	// Markers holds sentinel errors for errors.Is matching
+	// Reason is a stable, machine-readable identifier of the error cause,
+	// such as "USER_DISABLED". Together with Domain it forms the error identity
+	// reported in the "error_info" response object.
+	Reason string
+
+	// Code is a stable machine-readable error code, such as "USER_NOT_FOUND",
+	// reported as "code" in responses. See the Code function for its fallbacks.
+	Code string
+
+	// ExitCode, if non-zero, is the process exit code HandleCLI returns,
+	// independent of the HTTP status mapping.
+	ExitCode int
+
+	// Markers holds sentinel errors for errors.Is matching.
+	// Set it while building an error; to inspect an existing error use the
+	// Markers function, which returns a copy, or HasMarker.
 	Markers []error
+
+	// LogLevel, if set, is the level the error is logged at,
+	// taking precedence over the level configured for LogErr.
+	LogLevel *slog.Level
+
+	// Headers are copied onto the HTTP response by HandleHTTP
+	Headers http.Header
+
+	// Metadata is sent as HTTP response trailers by HandleHTTPErr
+	// when HandleHTTPErrOpts.IncludeMeta is set. It never appears in the body.
+	Metadata map[string]string
+
+	// Stack is the goroutine stack captured by WithStack, if any
+	Stack []byte
+
+	// CreatedAt is when the error was first created.
+	// Wrap keeps the time of the wrapped error.
+	CreatedAt time.Time
+
+	// lazyUserDetails computes UserDetails on first use when it is nil.
+	// See WithUserDetailsFunc.
+	lazyUserDetails *lazyDetails
+
+	// depth is the number of Wrap layers in the chain.
+	depth int
+
+	// inheritedDetails is the number of leading LogDetails entries
+	// copied from the wrapped errors by Wrap or CollectErrors.
+	inheritedDetails int
 }
 
 // Error implements the error interface.
@@ -61,38 +158,121 @@ func (e *Error) Unwrap() error {
 	return e.Internal
 }
 
-// Is implements errors.Is matching for marked sentinel errors
+// Is implements errors.Is matching for marked sentinel errors.
+// An error always matches itself and the broad sentinels registered
+// with RegisterAlias for the sentinels it matches.
 func (e *Error) Is(target error) bool {
-	// Check if target matches any marker
+	if t, ok := target.(*Error); ok && t == e {
+		return true
+	}
+	if e.HasMarker(target) {
+		return true
+	}
+	// Fall back to unwrapping Internal
+	if errors.Is(e.Internal, target) {
+		return true
+	}
+	return e.matchesAlias(target)
+}
+
+// lazyDetails is a user details value computed at most once. It is shared
+// by the layers wrapping the error it was set on.
+type lazyDetails struct {
+	once  sync.Once
+	fn    func() any
+	value any
+}
+
+func (l *lazyDetails) get() any {
+	l.once.Do(func() { l.value = l.fn() })
+	return l.value
+}
+
+// userDetails returns UserDetails, computing it with the function set by
+// WithUserDetailsFunc if it is nil.
+func (e *Error) userDetails() any {
+	if e.UserDetails == nil && e.lazyUserDetails != nil {
+		return e.lazyUserDetails.get()
+	}
+	return e.UserDetails
+}
+
+// Format implements fmt.Formatter.
+// %v and %s print the internal message, %q prints it quoted.
+// %+v additionally prints the domain, markers and stack when present.
+// Markers are printed in a stable order, independent of marking order.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			fmt.Fprint(s, e.Error())
+			if e.Domain != "" {
+				fmt.Fprintf(s, "\ndomain: %s", e.Domain)
+			}
+			if len(e.Markers) > 0 {
+				names := make([]string, 0, len(e.Markers))
+				for _, m := range sortedMarkers(e.Markers) {
+					names = append(names, m.Error())
+				}
+				fmt.Fprintf(s, "\nmarkers: %s", strings.Join(names, ", "))
+			}
+			if len(e.Stack) > 0 {
+				fmt.Fprintf(s, "\nstack:\n%s", e.Stack)
+			}
+			return
+		}
+		fmt.Fprint(s, e.Error())
+	case 's':
+		fmt.Fprint(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// HasMarker reports whether any of the error's markers matches target.
+// Unlike errors.Is, the Internal chain is not consulted.
+// A marker referring to the error itself is skipped to avoid infinite recursion.
+func (e *Error) HasMarker(target error) bool {
 	for _, m := range e.Markers {
+		if self, ok := m.(*Error); ok && self == e {
+			continue
+		}
 		if errors.Is(m, target) {
 			return true
 		}
 	}
-	// Fall back to unwrapping Internal
-	return errors.Is(e.Internal, target)
+	return false
 }
 
-// Option can be provided in args to New and Newf
-// to change error's parameters
-type Option func(*Error)
+// Temporary reports whether the error is transient and the operation may be
+// retried: it matches ErrRateLimited, ErrRemoteServiceErr or ErrDeadlineExceeded,
+// through its markers or its Internal chain.
+// It makes *Error satisfy the Temporary method of net.Error.
+func (e *Error) Temporary() bool {
+	return IsAny(e, ErrRateLimited, ErrRemoteServiceErr, ErrDeadlineExceeded)
+}
 
 // Newf creates a new *Error with formatted internal message and optional wrapped error.
 // Usage examples:
 //
 //	Newf("something failed: %w", err) // wraps err
 //	Newf("simple error without wrapping")
+//
+// An *Error wrapped with %w is preserved the way Wrap preserves it.
+// With several %w operands, the first *Error is preserved and the markers
+// of all of them are kept.
 func Newf(internalMsgFmt string, args ...any) error {
-	e := &Error{LogDetails: make([]any, 0)}
+	e := &Error{LogDetails: make([]any, 0), CreatedAt: Clock()}
 	cleanArgs := make([]any, 0, len(args))
+	opts := make([]Option, 0)
 
 	for _, arg := range args {
 		if opt, ok := arg.(Option); ok {
-			opt(e)
+			opts = append(opts, opt)
 			continue
 		}
 		if opt, ok := arg.(func(*Error)); ok {
-			opt(e)
+			opts = append(opts, opt)
 			continue
 		}
 		cleanArgs = append(cleanArgs, arg)
@@ -102,6 +282,38 @@ func Newf(internalMsgFmt string, args ...any) error {
 	if e.Internal == nil {
 		return nil
 	}
+
+	var wrapped []error
+	switch u := e.Internal.(type) {
+	case interface{ Unwrap() []error }:
+		wrapped = u.Unwrap()
+	case interface{ Unwrap() error }:
+		wrapped = []error{u.Unwrap()}
+	}
+	inherited := false
+	for _, w := range wrapped {
+		prev, ok := w.(*Error)
+		if !ok {
+			continue
+		}
+		if !inherited {
+			inherit(e, prev)
+			inherited = true
+			continue
+		}
+		for _, m := range prev.Markers {
+			e.Markers = appendMarker(e.Markers, m)
+		}
+	}
+	var inner *Error
+	if errors.As(e.Internal, &inner) && !inner.CreatedAt.IsZero() {
+		e.CreatedAt = inner.CreatedAt
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	applyDefaultDomain(e)
 	return e
 }
 
@@ -110,68 +322,292 @@ func New(internalMsg string, opts ...Option) error {
 	err := &Error{
 		Internal:   errors.New(internalMsg),
 		LogDetails: make([]any, 0),
+		CreatedAt:  Clock(),
 	}
 
 	for _, opt := range opts {
 		opt(err)
 	}
+	applyDefaultDomain(err)
 	return err
 }
 
 // Wrap wraps an error with additional context string.
 // Returns nil if err is nil.
 // Preserves original error for errors.Is/As.
+// Wrapping an *Error inherits its markers, visibility and details; in
+// particular an exposed inner error stays exposed, including the new
+// context message. Use WrapResetVisibility to make the result private.
 func Wrap(err error, msg string, opts ...Option) error {
 	if err == nil {
 		return nil
 	}
 
 	e := &Error{
-		Internal:   fmt.Errorf("%s: %w", msg, err),
 		LogDetails: make([]any, 0),
+		CreatedAt:  Clock(),
+		depth:      1,
+	}
+	var inner *Error
+	if errors.As(err, &inner) {
+		if !inner.CreatedAt.IsZero() {
+			e.CreatedAt = inner.CreatedAt
+		}
+		e.depth = inner.depth + 1
+	}
+	if MaxWrapDepth > 0 && e.depth > MaxWrapDepth {
+		e.Internal = collapse(msg, err, inner, e.depth)
+	} else {
+		e.Internal = fmt.Errorf("%s: %w", msg, err)
 	}
 
 	// Preserve markers if wrapping another *Error
 	if prev, ok := err.(*Error); ok {
-		e.Markers = prev.Markers
-		e.ExposeInternal = prev.ExposeInternal
-		e.SafeMessage = prev.SafeMessage
-		e.UserDetails = prev.UserDetails
-		e.Domain = prev.Domain
-		if prev.LogDetails != nil {
-			e.LogDetails = prev.LogDetails
-		}
+		inherit(e, prev)
 	}
 
 	for _, opt := range opts {
 		opt(e)
 	}
+	applyDefaultDomain(e)
+
+	return e
+}
+
+// collapsedError is the Internal of a Wrap layer past MaxWrapDepth.
+type collapsedError struct {
+	msg   string
+	depth int
+	root  string
+	err   error
+}
+
+func (c *collapsedError) Error() string {
+	return fmt.Sprintf("%s: ...%d layers...: %s", c.msg, c.depth-1, c.root)
+}
+
+func (c *collapsedError) Unwrap() error {
+	return c.err
+}
+
+// collapse builds the Internal of a Wrap layer past MaxWrapDepth. The root
+// message is taken from an already collapsed inner layer when possible, so
+// each further Wrap stays cheap.
+func collapse(msg string, err error, inner *Error, depth int) error {
+	if inner != nil {
+		if c, ok := inner.Internal.(*collapsedError); ok {
+			return &collapsedError{msg: msg, depth: depth, root: c.root, err: err}
+		}
+	}
+	root := err
+	for next := errors.Unwrap(root); next != nil; next = errors.Unwrap(root) {
+		root = next
+	}
+	return &collapsedError{msg: msg, depth: depth, root: root.Error(), err: err}
+}
+
+// inherit copies the markers, visibility, identity and details of prev,
+// an *Error being wrapped, onto e.
+func inherit(e, prev *Error) {
+	e.Markers = append([]error{}, prev.Markers...)
+	e.ExposeInternal = prev.ExposeInternal
+	e.SafeMessage = prev.SafeMessage
+	e.UserDetails = prev.UserDetails
+	e.lazyUserDetails = prev.lazyUserDetails
+	e.Domain = prev.Domain
+	e.Reason = prev.Reason
+	e.Code = prev.Code
+	e.ExitCode = prev.ExitCode
+	e.Cause = prev.Cause
+	e.LogLevel = prev.LogLevel
+	e.Hints = append([]string{}, prev.Hints...)
+	e.Headers = prev.Headers.Clone()
+	e.Metadata = maps.Clone(prev.Metadata)
+	e.Stack = prev.Stack
+	// Copy so options appending to LogDetails never write into
+	// the backing array of the wrapped error.
+	e.LogDetails = append(e.LogDetails, prev.LogDetails...)
+	e.inheritedDetails = len(prev.LogDetails)
+}
+
+// WrapFunc is Wrap with a lazily computed message:
+// msgFn is only called when err is non-nil.
+func WrapFunc(err error, msgFn func() string, opts ...Option) error {
+	if err == nil {
+		return nil
+	}
+	return Wrap(err, msgFn(), opts...)
+}
+
+// WrapIf wraps err with msg only when cond is true.
+// Otherwise err is returned untouched. Returns nil if err is nil.
+func WrapIf(cond bool, err error, msg string, opts ...Option) error {
+	if !cond {
+		return err
+	}
+	return Wrap(err, msg, opts...)
+}
+
+// WrapAll wraps every non-nil error in errs with the message returned by
+// msgf for its index. Nil entries are kept in place.
+// The input slice is not modified.
+func WrapAll(errs []error, msgf func(i int) string, opts ...Option) []error {
+	wrapped := make([]error, len(errs))
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		wrapped[i] = Wrap(err, msgf(i), opts...)
+	}
+	return wrapped
+}
+
+// Join combines errs into a single *Error, discarding nils.
+// Returns nil if every error is nil.
+// The result carries the union of the joined errors' markers,
+// with duplicates removed, and errors.Is matches every joined error.
+func Join(errs ...error) error {
+	joined := errors.Join(errs...)
+	if joined == nil {
+		return nil
+	}
+
+	e := &Error{
+		Internal:   joined,
+		LogDetails: make([]any, 0),
+		CreatedAt:  Clock(),
+	}
+	for _, err := range errs {
+		for _, m := range Markers(err) {
+			e.Markers = appendMarker(e.Markers, m)
+		}
+	}
+	return e
+}
+
+// CollectErrors combines the results of concurrent workers into a single
+// error, like Join, additionally marked with the sentinel of the most severe
+// member as chosen by FirstError. A member matching ErrInternal or no known
+// sentinel takes precedence: it marks the result ErrInternal, so it maps
+// to 500 even next to a 504 member.
+// The LogDetails of all members are aggregated; GetAllDetails still reports
+// each of them once. Returns nil if every error is nil.
+func CollectErrors(errs ...error) error {
+	joined := Join(errs...)
+	if joined == nil {
+		return nil
+	}
+	e := joined.(*Error)
+
+	var sentinel error = ErrInternal
+	if !failedInternally(errs) {
+		sentinel = canonicalSentinel(FirstError(errs...))
+	}
+	e.Markers = appendMarker(e.Markers, sentinel)
 
+	for _, err := range errs {
+		if err != nil {
+			e.LogDetails = append(e.LogDetails, GetAllDetails(err)...)
+		}
+	}
+	// The members stay in the chain, so their details are reported once.
+	e.inheritedDetails = len(e.LogDetails)
+	return e
+}
+
+// Merge combines two errors about the same operation. The result keeps
+// primary's message and markers, fills in the Domain, Code and SafeMessage
+// primary lacks from secondary, and appends secondary's markers and details,
+// as reported by GetAllDetails. If either error is nil, the other is returned.
+func Merge(primary, secondary error) error {
+	if primary == nil {
+		return secondary
+	}
+	if secondary == nil {
+		return primary
+	}
+
+	e := clone(primary)
+	var sec *Error
+	if errors.As(secondary, &sec) {
+		if e.Domain == "" {
+			e.Domain = sec.Domain
+		}
+		if e.Code == "" {
+			e.Code = sec.Code
+		}
+		if e.SafeMessage == "" {
+			e.SafeMessage = sec.SafeMessage
+		}
+	}
+	for _, m := range Markers(secondary) {
+		e.Markers = appendMarker(e.Markers, m)
+	}
+	e.LogDetails = append(e.LogDetails, GetAllDetails(secondary)...)
 	return e
 }
 
+// failedInternally reports whether any of errs matches ErrInternal or no
+// known sentinel. Such a failure takes precedence over every other status,
+// even a more severe one like 504.
+func failedInternally(errs []error) bool {
+	for _, err := range errs {
+		if err != nil && (errors.Is(err, ErrInternal) || canonicalSentinel(err) == nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedMarkers returns a copy of markers in a stable order: by canonical
+// name, or by message for markers without one. Nil markers are dropped.
+func sortedMarkers(markers []error) []error {
+	sorted := make([]error, 0, len(markers))
+	for _, m := range markers {
+		if m != nil {
+			sorted = append(sorted, m)
+		}
+	}
+	slices.SortStableFunc(sorted, func(a, b error) int {
+		return strings.Compare(markerName(a), markerName(b))
+	})
+	return sorted
+}
+
+// markerName returns the canonical name of marker, or its message
+// if it has none. A nil marker has an empty name.
+func markerName(marker error) string {
+	if marker == nil {
+		return ""
+	}
+	if name := CanonicalName(marker); name != "" {
+		return name
+	}
+	return marker.Error()
+}
+
+// appendMarker appends marker unless an equivalent one is already present.
+func appendMarker(markers []error, marker error) []error {
+	for _, m := range markers {
+		if errors.Is(m, marker) {
+			return markers
+		}
+	}
+	return append(markers, marker)
+}
+
 // Mark marks an error with a sentinel error for errors.Is matching.
 // Returns nil if err is nil.
 // The original error message is preserved; marker is only for Is() matching.
+// A nil marker is ignored: the result is a copy of err with opts applied.
 func Mark(err error, marker error, opts ...Option) error {
 	if err == nil {
 		return nil
 	}
 
-	e, ok := err.(*Error)
-	if !ok {
-		// Wrap foreign error into *Error
-		e = &Error{
-			Internal:   err,
-			LogDetails: make([]any, 0),
-			Markers:    []error{marker},
-		}
-	} else {
-		// Clone to avoid mutating original
-		clone := *e
-		clone.Markers = append([]error{}, e.Markers...)
-		clone.Markers = append(clone.Markers, marker)
-		e = &clone
+	e := clone(err)
+	if marker != nil {
+		e.Markers = append(e.Markers, marker)
 	}
 
 	for _, opt := range opts {
@@ -180,3 +616,136 @@ func Mark(err error, marker error, opts ...Option) error {
 
 	return e
 }
+
+// MarkMany marks err with every non-nil marker, in order.
+// Returns nil if err is nil.
+func MarkMany(err error, markers ...error) error {
+	if err == nil {
+		return nil
+	}
+
+	e := clone(err)
+	for _, marker := range markers {
+		if marker != nil {
+			e.Markers = append(e.Markers, marker)
+		}
+	}
+	return e
+}
+
+// StripMarkers returns a copy of err keeping only the markers that match
+// one of keep, e.g. to drop infrastructure markers at a trust boundary.
+// Markers of *Error layers wrapped in err are stripped too: those layers are
+// copied with their markers filtered, so the copy no longer matches them with
+// errors.Is, while details, hints and the other errors of the chain are kept.
+// Errors other than *Error are returned as is. Returns nil if err is nil.
+func StripMarkers(err error, keep ...error) error {
+	if _, ok := err.(*Error); !ok {
+		return err
+	}
+	return stripChain(err, keep)
+}
+
+// stripChain rebuilds the chain of err with markers not in keep removed.
+// Layers that need no change are returned as is.
+func stripChain(err error, keep []error) error {
+	switch x := err.(type) {
+	case *Error:
+		e := clone(x)
+		kept := e.Markers[:0]
+		for _, m := range e.Markers {
+			if IsAny(m, keep...) {
+				kept = append(kept, m)
+			}
+		}
+		e.Markers = kept
+		e.Internal = stripChain(e.Internal, keep)
+		return e
+
+	case interface{ Unwrap() []error }:
+		return stripWrapper(err, x.Unwrap(), keep)
+
+	case interface{ Unwrap() error }:
+		return stripWrapper(err, []error{x.Unwrap()}, keep)
+	}
+	return err
+}
+
+// stripWrapper strips the errors wrapped by a foreign err, which cannot be
+// copied, by putting a strippedError in its place when any of them changed.
+func stripWrapper(err error, inner []error, keep []error) error {
+	stripped := make([]error, len(inner))
+	changed := false
+	for i, in := range inner {
+		stripped[i] = stripChain(in, keep)
+		changed = changed || stripped[i] != in
+	}
+	if !changed {
+		return err
+	}
+	return &strippedError{err: err, inner: stripped}
+}
+
+// strippedError stands in for a foreign wrapper whose chain StripMarkers
+// rebuilt: it has the wrapper's message and matches the wrapper itself,
+// but unwraps to the stripped errors.
+type strippedError struct {
+	err   error
+	inner []error
+}
+
+func (s *strippedError) Error() string {
+	return s.err.Error()
+}
+
+func (s *strippedError) Unwrap() []error {
+	return s.inner
+}
+
+func (s *strippedError) Is(target error) bool {
+	if reflect.TypeOf(target).Comparable() && s.err == target {
+		return true
+	}
+	is, ok := s.err.(interface{ Is(error) bool })
+	return ok && is.Is(target)
+}
+
+func (s *strippedError) As(target any) bool {
+	val := reflect.ValueOf(target).Elem()
+	if reflect.TypeOf(s.err).AssignableTo(val.Type()) {
+		val.Set(reflect.ValueOf(s.err))
+		return true
+	}
+	as, ok := s.err.(interface{ As(any) bool })
+	return ok && as.As(target)
+}
+
+// MarkIf marks err with marker only when cond is true.
+// Otherwise err is returned untouched. Returns nil if err is nil.
+func MarkIf(cond bool, err error, marker error, opts ...Option) error {
+	if !cond {
+		return err
+	}
+	return Mark(err, marker, opts...)
+}
+
+// clone returns a copy of err as *Error that can be modified without
+// affecting the original. Foreign errors are wrapped into a new *Error.
+func clone(err error) *Error {
+	e, ok := err.(*Error)
+	if !ok {
+		return &Error{
+			Internal:   err,
+			LogDetails: make([]any, 0),
+			CreatedAt:  Clock(),
+		}
+	}
+
+	cp := *e
+	cp.LogDetails = append([]any{}, e.LogDetails...)
+	cp.Markers = append([]error{}, e.Markers...)
+	cp.Hints = append([]string{}, e.Hints...)
+	cp.Headers = e.Headers.Clone()
+	cp.Metadata = maps.Clone(e.Metadata)
+	return &cp
+}
@@ -1,9 +1,15 @@
 package errs_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/4nd3r5on/errs"
 )
@@ -96,6 +102,26 @@ func TestWrap(t *testing.T) {
 		}
 	})
 
+	t.Run("does not alias LogDetails of wrapped Error", func(t *testing.T) {
+		details := make([]any, 0, 8)
+		details = append(details, "key", "value")
+		base := errs.New("base", func(e *errs.Error) {
+			e.LogDetails = details
+		})
+
+		wrapped := errs.Wrap(base, "context", func(e *errs.Error) {
+			e.LogDetails = append(e.LogDetails, "extra", 1)
+		})
+
+		inner := base.(*errs.Error).LogDetails
+		if len(inner) != 2 || inner[:cap(inner)][2] != nil {
+			t.Errorf("inner LogDetails mutated: %v", inner[:cap(inner)])
+		}
+		if got := wrapped.(*errs.Error).LogDetails; len(got) != 4 {
+			t.Errorf("wrapped LogDetails = %v, want 4 entries", got)
+		}
+	})
+
 	t.Run("preserves fields from wrapped Error", func(t *testing.T) {
 		base := errs.New("base", func(e *errs.Error) {
 			e.ExposeInternal = true
@@ -126,6 +152,266 @@ func TestWrap(t *testing.T) {
 	})
 }
 
+func TestWrapResetVisibility(t *testing.T) {
+	inner := errs.Mark(errors.New("email is taken"), errs.ErrExists, func(e *errs.Error) {
+		e.ExposeInternal = true
+	})
+
+	t.Run("inherits visibility by default", func(t *testing.T) {
+		err := errs.Wrap(inner, "register")
+
+		if !err.(*errs.Error).ExposeInternal {
+			t.Error("ExposeInternal = false, want inherited true")
+		}
+	})
+
+	t.Run("resets to private", func(t *testing.T) {
+		err := errs.Wrap(inner, "call accounts", errs.WrapResetVisibility())
+
+		if err.(*errs.Error).ExposeInternal {
+			t.Error("ExposeInternal = true, want false")
+		}
+		if got := errs.EffectiveSafeMessage(err); got != "The resource already exists" {
+			t.Errorf("EffectiveSafeMessage() = %q, want canonical message", got)
+		}
+		if !inner.(*errs.Error).ExposeInternal {
+			t.Error("WrapResetVisibility modified the wrapped error")
+		}
+	})
+}
+
+func TestWrapAll(t *testing.T) {
+	t.Run("wraps non-nil errors and keeps nils in place", func(t *testing.T) {
+		base0 := errors.New("timeout")
+		base2 := errors.New("refused")
+
+		got := errs.WrapAll([]error{base0, nil, base2}, func(i int) string {
+			return fmt.Sprintf("worker %d", i)
+		})
+
+		if len(got) != 3 {
+			t.Fatalf("len(got) = %d, want 3", len(got))
+		}
+		if got[1] != nil {
+			t.Errorf("got[1] = %v, want nil", got[1])
+		}
+		if want := "worker 0: timeout"; got[0].Error() != want {
+			t.Errorf("got[0].Error() = %q, want %q", got[0].Error(), want)
+		}
+		if want := "worker 2: refused"; got[2].Error() != want {
+			t.Errorf("got[2].Error() = %q, want %q", got[2].Error(), want)
+		}
+		if !errors.Is(got[2], base2) {
+			t.Error("errors.Is(got[2], base2) = false, want true")
+		}
+	})
+}
+
+func TestJoin(t *testing.T) {
+	t.Run("returns nil when all errors are nil", func(t *testing.T) {
+		if got := errs.Join(nil, nil); got != nil {
+			t.Errorf("Join(nil, nil) = %v, want nil", got)
+		}
+	})
+
+	t.Run("dedupes shared markers", func(t *testing.T) {
+		e1 := errs.Mark(errors.New("name is empty"), errs.ErrInvalidArgument)
+		e2 := errs.Mark(errors.New("age is negative"), errs.ErrInvalidArgument)
+		e3 := errs.Mark(errors.New("email is malformed"), errs.ErrInvalidArgument)
+
+		joined := errs.Join(e1, nil, e2, e3)
+
+		markers := errs.Markers(joined)
+		if len(markers) != 1 || markers[0] != errs.ErrInvalidArgument {
+			t.Errorf("Markers(joined) = %v, want [%v]", markers, errs.ErrInvalidArgument)
+		}
+		if !errors.Is(joined, errs.ErrInvalidArgument) {
+			t.Error("errors.Is(joined, ErrInvalidArgument) = false, want true")
+		}
+		for _, err := range []error{e1, e2, e3} {
+			if !errors.Is(joined, err) {
+				t.Errorf("errors.Is(joined, %v) = false, want true", err)
+			}
+		}
+	})
+
+	t.Run("keeps distinct markers", func(t *testing.T) {
+		joined := errs.Join(
+			errs.Mark(errors.New("a"), errs.ErrNotFound),
+			errs.Mark(errors.New("b"), errs.ErrInvalidArgument),
+		)
+
+		if got := len(errs.Markers(joined)); got != 2 {
+			t.Errorf("len(Markers(joined)) = %d, want 2", got)
+		}
+	})
+}
+
+func TestCollectErrors(t *testing.T) {
+	t.Run("returns nil when all errors are nil", func(t *testing.T) {
+		if got := errs.CollectErrors(nil, nil); got != nil {
+			t.Errorf("CollectErrors(nil, nil) = %v, want nil", got)
+		}
+	})
+
+	t.Run("maps to 500 when any worker failed internally", func(t *testing.T) {
+		notFound := errs.Mark(errors.New("item 3 missing"), errs.ErrNotFound, func(e *errs.Error) {
+			e.LogDetails = []any{"item", 3}
+		})
+		internal := errs.New("write failed", func(e *errs.Error) {
+			e.LogDetails = []any{"shard", "b"}
+		})
+
+		err := errs.CollectErrors(notFound, nil, internal)
+
+		if got := errs.GetHTTPCode(err); got != 500 {
+			t.Errorf("GetHTTPCode() = %d, want 500", got)
+		}
+		if !errors.Is(err, errs.ErrInternal) {
+			t.Error("errors.Is(err, ErrInternal) = false, want true")
+		}
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Error("errors.Is(err, ErrNotFound) = false, want true")
+		}
+		want := []any{"item", 3, "shard", "b"}
+		if got := err.(*errs.Error).LogDetails; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("LogDetails = %v, want %v", got, want)
+		}
+		if got := errs.GetAllDetails(err); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("GetAllDetails() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("internal failure outranks more severe statuses", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			members []error
+		}{
+			{"unknown next to not found", []error{errs.Mark(errors.New("a"), errs.ErrNotFound), errors.New("boom")}},
+			{"unknown next to timeout", []error{errs.Mark(errors.New("a"), errs.ErrDeadlineExceeded), errors.New("boom")}},
+			{"internal next to not implemented", []error{
+				errs.Mark(errors.New("a"), errs.ErrNotImplemented),
+				errs.Mark(errors.New("b"), errs.ErrInternal),
+			}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := errs.CollectErrors(tt.members...)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodPost, "/batch", nil)
+				errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+				if w.Code != http.StatusInternalServerError {
+					t.Errorf("status = %d, want 500", w.Code)
+				}
+				if want := `{"error":"An internal error occurred"}`; w.Body.String() != want {
+					t.Errorf("body = %s, want %s", w.Body.String(), want)
+				}
+			})
+		}
+	})
+
+	t.Run("maps to the most severe client error", func(t *testing.T) {
+		err := errs.CollectErrors(
+			errs.Mark(errors.New("a"), errs.ErrNotFound),
+			errs.Mark(errors.New("b"), errs.ErrExists),
+		)
+
+		if got := errs.GetHTTPCode(err); got != 409 {
+			t.Errorf("GetHTTPCode() = %d, want 409", got)
+		}
+	})
+}
+
+func TestMaxWrapDepth(t *testing.T) {
+	orig := errs.MaxWrapDepth
+	t.Cleanup(func() { errs.MaxWrapDepth = orig })
+	errs.MaxWrapDepth = 50
+
+	root := errors.New("connection refused")
+	err := errs.Mark(root, errs.ErrRemoteServiceErr)
+	for i := range 10000 {
+		err = errs.Wrap(err, fmt.Sprintf("layer %d", i))
+	}
+
+	msg := err.Error()
+	if len(msg) > 100 {
+		t.Errorf("len(Error()) = %d, want at most 100: %q", len(msg), msg)
+	}
+	if want := "layer 9999: ...9999 layers...: connection refused"; msg != want {
+		t.Errorf("Error() = %q, want %q", msg, want)
+	}
+	if !errors.Is(err, root) {
+		t.Error("errors.Is(err, root) = false, want true")
+	}
+	if !errors.Is(err, errs.ErrRemoteServiceErr) {
+		t.Error("errors.Is(err, ErrRemoteServiceErr) = false, want true")
+	}
+
+	t.Run("keeps shallow chains intact", func(t *testing.T) {
+		err := errs.Wrap(errs.Wrap(root, "dial"), "fetch")
+		if want := "fetch: dial: connection refused"; err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err.Error(), want)
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("returns the other error when one is nil", func(t *testing.T) {
+		base := errors.New("base")
+		if got := errs.Merge(nil, base); got != base {
+			t.Errorf("Merge(nil, base) = %v, want base", got)
+		}
+		if got := errs.Merge(base, nil); got != base {
+			t.Errorf("Merge(base, nil) = %v, want base", got)
+		}
+	})
+
+	t.Run("fills in metadata from secondary", func(t *testing.T) {
+		primary := errs.New("load invoice 7", errs.WithMarkers(errs.ErrNotFound))
+		secondary := errs.New("billing lookup",
+			errs.WithCode("INVOICE_MISSING"),
+			errs.WithMarkers(errs.ErrOutdated),
+			func(e *errs.Error) {
+				e.Domain = "billing"
+				e.SafeMessage = "Invoice not found"
+				e.LogDetails = []any{"invoice", 7}
+			},
+		)
+
+		err := errs.Merge(primary, secondary)
+
+		if err.Error() != "load invoice 7" {
+			t.Errorf("Error() = %q, want primary's message", err.Error())
+		}
+		e := err.(*errs.Error)
+		if e.Domain != "billing" || e.Code != "INVOICE_MISSING" || e.SafeMessage != "Invoice not found" {
+			t.Errorf("Domain, Code, SafeMessage = %q, %q, %q, want secondary's", e.Domain, e.Code, e.SafeMessage)
+		}
+		if !errors.Is(err, errs.ErrNotFound) || !errors.Is(err, errs.ErrOutdated) {
+			t.Error("merged error does not match both markers")
+		}
+		want := []any{"invoice", 7}
+		if fmt.Sprint(e.LogDetails) != fmt.Sprint(want) {
+			t.Errorf("LogDetails = %v, want %v", e.LogDetails, want)
+		}
+		if primary.(*errs.Error).Domain != "" {
+			t.Error("Merge modified primary")
+		}
+	})
+
+	t.Run("keeps primary's metadata", func(t *testing.T) {
+		primary := errs.New("a", errs.WithCode("A"))
+		secondary := errs.New("b", errs.WithCode("B"))
+
+		if got := errs.Merge(primary, secondary).(*errs.Error).Code; got != "A" {
+			t.Errorf("Code = %q, want %q", got, "A")
+		}
+	})
+}
+
 func TestMark(t *testing.T) {
 	t.Run("returns nil when err is nil", func(t *testing.T) {
 		sentinel := errors.New("sentinel")
@@ -224,9 +510,8 @@ func TestMark(t *testing.T) {
 		marked2 := errs.Mark(base, marker2)
 
 		// base should not have any markers
-		asBase, _ := base.(*errs.Error)
-		if len(asBase.Markers) != 0 {
-			t.Errorf("original error mutated: markers = %v", asBase.Markers)
+		if markers := errs.Markers(base); len(markers) != 0 {
+			t.Errorf("original error mutated: markers = %v", markers)
 		}
 
 		// marked1 should only have marker1
@@ -241,6 +526,497 @@ func TestMark(t *testing.T) {
 	})
 }
 
+func TestMarkNil(t *testing.T) {
+	t.Run("Mark ignores a nil marker", func(t *testing.T) {
+		base := errs.New("base", errs.WithMarkers(errs.ErrNotFound))
+		marked := errs.Mark(base, nil)
+
+		if !errs.Equal(marked, base) {
+			t.Errorf("Mark(err, nil) = %+v, want equivalent to %+v", marked, base)
+		}
+		if got := len(errs.Markers(marked)); got != 1 {
+			t.Errorf("len(Markers) = %d, want 1", got)
+		}
+		if errors.Is(marked, nil) {
+			t.Error("errors.Is(marked, nil) = true, want false")
+		}
+	})
+
+	t.Run("MarkMany filters nil markers", func(t *testing.T) {
+		marked := errs.MarkMany(errors.New("base"), errs.ErrNotFound, nil, errs.ErrOutdated, nil)
+
+		markers := errs.Markers(marked)
+		if len(markers) != 2 || markers[0] != errs.ErrNotFound || markers[1] != errs.ErrOutdated {
+			t.Errorf("Markers = %v, want [ErrNotFound ErrOutdated]", markers)
+		}
+	})
+
+	t.Run("WithMarkers and factory Mark filter nil markers", func(t *testing.T) {
+		built := map[string]error{
+			"WithMarkers": errs.New("x", errs.WithMarkers(nil, errs.ErrNotFound)),
+			"factory":     errs.F().Message("x").Mark(nil).MarkIf(true, nil, errs.ErrNotFound).Err(),
+		}
+		for name, err := range built {
+			if markers := errs.Markers(err); len(markers) != 1 || markers[0] != errs.ErrNotFound {
+				t.Errorf("%s: Markers = %v, want [ErrNotFound]", name, markers)
+			}
+		}
+	})
+
+	t.Run("formatting tolerates a stored nil marker", func(t *testing.T) {
+		err := &errs.Error{Internal: errors.New("x"), Markers: []error{nil, errs.ErrNotFound}}
+
+		if got := fmt.Sprintf("%+v", err); !strings.Contains(got, "markers: "+errs.ErrNotFound.Error()) {
+			t.Errorf("%%+v = %q, want the non-nil marker listed", got)
+		}
+		_ = errs.Dump(err)
+		_ = errs.NormalizeForComparison(err)
+	})
+
+	t.Run("MarkMany returns nil when err is nil", func(t *testing.T) {
+		if got := errs.MarkMany(nil, errs.ErrNotFound); got != nil {
+			t.Errorf("MarkMany(nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestAddMarker(t *testing.T) {
+	t.Run("adds a marker while wrapping", func(t *testing.T) {
+		inner := errs.Mark(errors.New("no row"), errs.ErrNotFound)
+		err := errs.Wrap(inner, "load user", errs.AddMarker(errs.ErrOutdated))
+
+		if !errors.Is(err, errs.ErrNotFound) || !errors.Is(err, errs.ErrOutdated) {
+			t.Errorf("Markers = %v, want inner and added marker", errs.Markers(err))
+		}
+		if errors.Is(inner, errs.ErrOutdated) {
+			t.Error("AddMarker modified the wrapped error")
+		}
+	})
+
+	t.Run("works with New and Newf", func(t *testing.T) {
+		for _, err := range []error{
+			errs.New("boom", errs.AddMarker(errs.ErrRateLimited)),
+			errs.Newf("boom %d", 1, errs.AddMarker(errs.ErrRateLimited)),
+		} {
+			if !errors.Is(err, errs.ErrRateLimited) {
+				t.Errorf("errors.Is(%v, ErrRateLimited) = false, want true", err)
+			}
+		}
+	})
+
+	t.Run("ignores nil", func(t *testing.T) {
+		if got := errs.Markers(errs.New("boom", errs.AddMarker(nil))); len(got) != 0 {
+			t.Errorf("Markers = %v, want none", got)
+		}
+	})
+}
+
+func TestStripMarkers(t *testing.T) {
+	t.Run("keeps only listed markers", func(t *testing.T) {
+		base := errors.New("replica lag")
+		err := errs.Mark(errs.Mark(base, errs.ErrInternal), errs.ErrNotFound)
+
+		stripped := errs.StripMarkers(err, errs.ErrNotFound)
+
+		if errors.Is(stripped, errs.ErrInternal) {
+			t.Error("errors.Is(stripped, ErrInternal) = true, want false")
+		}
+		if !errors.Is(stripped, errs.ErrNotFound) {
+			t.Error("errors.Is(stripped, ErrNotFound) = false, want true")
+		}
+		if !errors.Is(stripped, base) {
+			t.Error("errors.Is(stripped, base) = false, want true")
+		}
+		if got := errs.GetHTTPCode(stripped); got != 404 {
+			t.Errorf("GetHTTPCode(stripped) = %d, want 404", got)
+		}
+		if got := errs.GetHTTPCode(err); got != 500 {
+			t.Errorf("original error changed: GetHTTPCode() = %d, want 500", got)
+		}
+	})
+
+	t.Run("strips markers of wrapped errors", func(t *testing.T) {
+		base := errors.New("replica lag")
+		err := errs.Mark(errs.Wrap(errs.Mark(base, errs.ErrInternal), "load user"), errs.ErrNotFound)
+
+		stripped := errs.StripMarkers(err, errs.ErrNotFound)
+
+		if errors.Is(stripped, errs.ErrInternal) {
+			t.Error("errors.Is(stripped, ErrInternal) = true, want false")
+		}
+		if !errors.Is(stripped, base) {
+			t.Error("errors.Is(stripped, base) = false, want true")
+		}
+		if got := errs.GetHTTPCode(stripped); got != 404 {
+			t.Errorf("GetHTTPCode(stripped) = %d, want 404", got)
+		}
+		if stripped.Error() != err.Error() {
+			t.Errorf("Error() = %q, want %q", stripped.Error(), err.Error())
+		}
+	})
+
+	t.Run("keeps wrapped markers listed in keep", func(t *testing.T) {
+		err := errs.Wrap(errs.MarkMany(errors.New("no row"), errs.ErrNotFound, errs.ErrInternal), "handler")
+
+		stripped := errs.StripMarkers(err, errs.ErrNotFound)
+
+		if !errors.Is(stripped, errs.ErrNotFound) {
+			t.Error("errors.Is(stripped, ErrNotFound) = false, want true")
+		}
+		if got := errs.GetHTTPCode(stripped); got != 404 {
+			t.Errorf("GetHTTPCode(stripped) = %d, want 404", got)
+		}
+	})
+
+	t.Run("keeps details and hints below stripped layers", func(t *testing.T) {
+		inner := errs.New("no row", errs.WithMarkers(errs.ErrInternal), errs.WithHint("check the id"), func(e *errs.Error) {
+			e.LogDetails = []any{"query", "select"}
+		})
+		wrapped := fmt.Errorf("repo: %w", inner)
+		err := errs.Wrap(wrapped, "load user", errs.AddMarker(errs.ErrNotFound), func(e *errs.Error) {
+			e.LogDetails = append(e.LogDetails, "id", 7)
+		})
+
+		stripped := errs.StripMarkers(err, errs.ErrNotFound)
+
+		if errors.Is(stripped, errs.ErrInternal) {
+			t.Error("errors.Is(stripped, ErrInternal) = true, want false")
+		}
+		if !errors.Is(stripped, wrapped) {
+			t.Error("errors.Is(stripped, wrapped) = false, want true")
+		}
+		if got, want := errs.GetAllDetails(stripped), errs.GetAllDetails(err); !reflect.DeepEqual(got, want) {
+			t.Errorf("GetAllDetails(stripped) = %v, want %v", got, want)
+		}
+		if got := errs.GetAllHints(stripped); !reflect.DeepEqual(got, []string{"check the id"}) {
+			t.Errorf("GetAllHints(stripped) = %v, want [check the id]", got)
+		}
+		if !strings.Contains(errs.Dump(stripped), "no row") {
+			t.Errorf("Dump(stripped) = %q, want the inner layer", errs.Dump(stripped))
+		}
+		if !errors.Is(err, errs.ErrInternal) {
+			t.Error("original error changed: ErrInternal no longer matches")
+		}
+	})
+
+	t.Run("returns nil when err is nil", func(t *testing.T) {
+		if got := errs.StripMarkers(nil, errs.ErrNotFound); got != nil {
+			t.Errorf("StripMarkers(nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestMarkIf(t *testing.T) {
+	t.Run("marks when condition is true", func(t *testing.T) {
+		base := errors.New("base")
+		marked := errs.MarkIf(true, base, errs.ErrNotFound)
+
+		if !errors.Is(marked, errs.ErrNotFound) {
+			t.Error("errors.Is(marked, ErrNotFound) = false, want true")
+		}
+		if !errors.Is(marked, base) {
+			t.Error("errors.Is(marked, base) = false, want true")
+		}
+	})
+
+	t.Run("returns err untouched when condition is false", func(t *testing.T) {
+		base := errors.New("base")
+		if got := errs.MarkIf(false, base, errs.ErrNotFound); got != base {
+			t.Errorf("MarkIf(false, base) = %v, want base", got)
+		}
+	})
+
+	t.Run("returns nil when err is nil", func(t *testing.T) {
+		if got := errs.MarkIf(true, nil, errs.ErrNotFound); got != nil {
+			t.Errorf("MarkIf(true, nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestWrapFunc(t *testing.T) {
+	t.Run("does not call msgFn when err is nil", func(t *testing.T) {
+		calls := 0
+		got := errs.WrapFunc(nil, func() string { calls++; return "context" })
+
+		if got != nil {
+			t.Errorf("WrapFunc(nil) = %v, want nil", got)
+		}
+		if calls != 0 {
+			t.Errorf("msgFn called %d times, want 0", calls)
+		}
+	})
+
+	t.Run("calls msgFn once when wrapping", func(t *testing.T) {
+		calls := 0
+		base := errors.New("base")
+		got := errs.WrapFunc(base, func() string { calls++; return "context" })
+
+		if calls != 1 {
+			t.Errorf("msgFn called %d times, want 1", calls)
+		}
+		if got.Error() != "context: base" {
+			t.Errorf("Error() = %q, want %q", got.Error(), "context: base")
+		}
+		if !errors.Is(got, base) {
+			t.Error("errors.Is(got, base) = false, want true")
+		}
+	})
+}
+
+func TestWrapIf(t *testing.T) {
+	t.Run("wraps when condition is true", func(t *testing.T) {
+		base := errors.New("base")
+		wrapped := errs.WrapIf(true, base, "context")
+
+		if got := wrapped.Error(); got != "context: base" {
+			t.Errorf("Error() = %q, want %q", got, "context: base")
+		}
+		if !errors.Is(wrapped, base) {
+			t.Error("errors.Is(wrapped, base) = false, want true")
+		}
+	})
+
+	t.Run("returns err untouched when condition is false", func(t *testing.T) {
+		base := errors.New("base")
+		if got := errs.WrapIf(false, base, "context"); got != base {
+			t.Errorf("WrapIf(false, base) = %v, want base", got)
+		}
+	})
+
+	t.Run("returns nil when err is nil", func(t *testing.T) {
+		if got := errs.WrapIf(true, nil, "context"); got != nil {
+			t.Errorf("WrapIf(true, nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestMarkersAccessor(t *testing.T) {
+	t.Run("Markers returns a defensive copy", func(t *testing.T) {
+		err := errs.Mark(errors.New("base"), errs.ErrNotFound)
+
+		markers := errs.Markers(err)
+		markers[0] = errs.ErrExists
+
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Error("mutating Markers() result changed the error")
+		}
+		if errors.Is(err, errs.ErrExists) {
+			t.Error("mutating Markers() result added a marker")
+		}
+	})
+
+	t.Run("Markers returns nil for foreign errors", func(t *testing.T) {
+		if got := errs.Markers(errors.New("base")); got != nil {
+			t.Errorf("Markers(foreign) = %v, want nil", got)
+		}
+	})
+
+	t.Run("HasMarker checks markers only", func(t *testing.T) {
+		base := errors.New("base")
+		err := errs.Mark(base, errs.ErrNotFound).(*errs.Error)
+
+		if !err.HasMarker(errs.ErrNotFound) {
+			t.Error("HasMarker(ErrNotFound) = false, want true")
+		}
+		if err.HasMarker(base) {
+			t.Error("HasMarker(base) = true, want false")
+		}
+	})
+}
+
+func TestIsSelf(t *testing.T) {
+	t.Run("error matches itself", func(t *testing.T) {
+		err := errs.New("base").(*errs.Error)
+
+		if !err.Is(err) {
+			t.Error("err.Is(err) = false, want true")
+		}
+		if !errors.Is(err, err) {
+			t.Error("errors.Is(err, err) = false, want true")
+		}
+	})
+
+	t.Run("self-referential marker does not recurse", func(t *testing.T) {
+		err := errs.New("base").(*errs.Error)
+		err.Markers = append(err.Markers, err)
+
+		if errors.Is(err, errs.ErrNotFound) {
+			t.Error("errors.Is(err, ErrNotFound) = true, want false")
+		}
+		if !errors.Is(err, err) {
+			t.Error("errors.Is(err, err) = false, want true")
+		}
+	})
+}
+
+func TestSetDefaultDomain(t *testing.T) {
+	errs.SetDefaultDomain("billing-svc")
+	t.Cleanup(func() { errs.SetDefaultDomain("") })
+
+	domainOf := func(err error) string {
+		var e *errs.Error
+		if !errors.As(err, &e) {
+			t.Fatalf("%T is not *errs.Error", err)
+		}
+		return e.Domain
+	}
+	tagged := func(e *errs.Error) { e.Domain = "invoices" }
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"New", errs.New("boom"), "billing-svc"},
+		{"Newf", errs.Newf("boom %d", 1), "billing-svc"},
+		{"Factory.Err", errs.F().Message("boom").Err(), "billing-svc"},
+		{"Wrap of foreign error", errs.Wrap(errors.New("boom"), "charge"), "billing-svc"},
+		{"tagged New", errs.New("boom", tagged), "invoices"},
+		{"tagged Factory.Err", errs.F().Message("boom").Domain("invoices").Err(), "invoices"},
+		{"Wrap keeps existing domain", errs.Wrap(errs.New("boom", tagged), "charge"), "invoices"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domainOf(tt.err); got != tt.want {
+				t.Errorf("Domain = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemporary(t *testing.T) {
+	t.Run("transient errors are temporary", func(t *testing.T) {
+		for _, marker := range []error{errs.ErrRateLimited, errs.ErrRemoteServiceErr, errs.ErrDeadlineExceeded} {
+			err := errs.Mark(errors.New("call failed"), marker).(*errs.Error)
+			if !err.Temporary() {
+				t.Errorf("Temporary() = false for %v, want true", marker)
+			}
+		}
+
+		wrapped := errs.Wrap(context.DeadlineExceeded, "fetch quote").(*errs.Error)
+		if !wrapped.Temporary() {
+			t.Error("Temporary() = false for wrapped context.DeadlineExceeded, want true")
+		}
+	})
+
+	t.Run("permanent errors are not temporary", func(t *testing.T) {
+		err := errs.Mark(errors.New("bad id"), errs.ErrInvalidArgument).(*errs.Error)
+		if err.Temporary() {
+			t.Error("Temporary() = true, want false")
+		}
+	})
+}
+
+func TestFormat(t *testing.T) {
+	base := errs.Mark(errors.New("row not found"), errs.ErrNotFound, func(e *errs.Error) {
+		e.Domain = "users"
+	})
+	err := errs.Wrap(base, "user lookup failed")
+
+	t.Run("%v and %s print the plain message", func(t *testing.T) {
+		want := "user lookup failed: row not found"
+		if got := fmt.Sprintf("%v", err); got != want {
+			t.Errorf("%%v = %q, want %q", got, want)
+		}
+		if got := fmt.Sprintf("%s", err); got != want {
+			t.Errorf("%%s = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("%+v prints domain and markers", func(t *testing.T) {
+		want := "user lookup failed: row not found\ndomain: users\nmarkers: not found"
+		if got := fmt.Sprintf("%+v", err); got != want {
+			t.Errorf("%%+v = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("%+v prints markers in a stable order", func(t *testing.T) {
+		errCustom := errors.New("quota exceeded")
+		a := errs.New("boom", errs.WithMarkers(errs.ErrNotFound, errCustom, errs.ErrInvalidArgument))
+		b := errs.New("boom", errs.WithMarkers(errs.ErrInvalidArgument, errs.ErrNotFound, errCustom))
+
+		got, other := fmt.Sprintf("%+v", a), fmt.Sprintf("%+v", b)
+		if got != other {
+			t.Errorf("%%+v differs by marking order:\n%s\n%s", got, other)
+		}
+		want := "boom\nmarkers: invalid argument, not found, quota exceeded"
+		if got != want {
+			t.Errorf("%%+v = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("%+v prints captured stack", func(t *testing.T) {
+		withStack := errs.New("boom", errs.WithStack())
+
+		got := fmt.Sprintf("%+v", withStack)
+		if !strings.HasPrefix(got, "boom\nstack:\n") {
+			t.Errorf("%%+v = %q, want stack section", got)
+		}
+		if !strings.Contains(got, "TestFormat") {
+			t.Errorf("%%+v stack does not mention the caller: %q", got)
+		}
+	})
+}
+
+// useClock makes errs.Clock return the times in order for the rest of the test.
+func useClock(t *testing.T, times ...time.Time) {
+	t.Helper()
+	orig := errs.Clock
+	t.Cleanup(func() { errs.Clock = orig })
+
+	errs.Clock = func() time.Time {
+		now := times[0]
+		if len(times) > 1 {
+			times = times[1:]
+		}
+		return now
+	}
+}
+
+func TestCreatedAt(t *testing.T) {
+	t1 := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	t.Run("New records creation time", func(t *testing.T) {
+		useClock(t, t1)
+
+		err := errs.New("boom").(*errs.Error)
+		if !err.CreatedAt.Equal(t1) {
+			t.Errorf("CreatedAt = %v, want %v", err.CreatedAt, t1)
+		}
+	})
+
+	t.Run("Factory records creation time", func(t *testing.T) {
+		useClock(t, t1)
+
+		err := errs.F().Message("boom").Err().(*errs.Error)
+		if !err.CreatedAt.Equal(t1) {
+			t.Errorf("CreatedAt = %v, want %v", err.CreatedAt, t1)
+		}
+	})
+
+	t.Run("Wrap keeps the original time", func(t *testing.T) {
+		useClock(t, t1, t2)
+
+		base := errs.Newf("query %d failed", 7)
+		wrapped := errs.Wrap(fmt.Errorf("repo: %w", base), "load user").(*errs.Error)
+
+		if !wrapped.CreatedAt.Equal(t1) {
+			t.Errorf("CreatedAt = %v, want %v", wrapped.CreatedAt, t1)
+		}
+	})
+
+	t.Run("Wrap of a foreign error records wrap time", func(t *testing.T) {
+		useClock(t, t2)
+
+		wrapped := errs.Wrap(errors.New("eof"), "read body").(*errs.Error)
+		if !wrapped.CreatedAt.Equal(t2) {
+			t.Errorf("CreatedAt = %v, want %v", wrapped.CreatedAt, t2)
+		}
+	})
+}
+
 func TestIntegration(t *testing.T) {
 	t.Run("realistic usage pattern", func(t *testing.T) {
 		var (
@@ -343,6 +1119,42 @@ func TestExistingFunctionality(t *testing.T) {
 		}
 	})
 
+	t.Run("Newf preserves a wrapped *Error", func(t *testing.T) {
+		inner := errs.Mark(errors.New("no row"), errs.ErrNotFound, func(e *errs.Error) {
+			e.SafeMessage = "User not found"
+			e.UserDetails = map[string]any{"user_id": 7}
+			e.LogDetails = []any{"table", "users"}
+			e.Domain = "users"
+		})
+		err := errs.Newf("load user %d: %w", 7, inner, errs.WithReason("USER_MISSING"))
+
+		asErr := err.(*errs.Error)
+		if !errors.Is(err, errs.ErrNotFound) || !asErr.HasMarker(errs.ErrNotFound) {
+			t.Error("marker ErrNotFound not preserved")
+		}
+		if asErr.SafeMessage != "User not found" || asErr.Domain != "users" || asErr.UserDetails == nil {
+			t.Errorf("SafeMessage, Domain or UserDetails not preserved: %+v", asErr)
+		}
+		if asErr.Reason != "USER_MISSING" {
+			t.Errorf("Reason = %q, want options applied after inheriting", asErr.Reason)
+		}
+		if got := errs.GetAllDetails(err); len(got) != 2 {
+			t.Errorf("GetAllDetails() = %v, want inner details once", got)
+		}
+	})
+
+	t.Run("Newf merges markers of several wrapped errors", func(t *testing.T) {
+		err := errs.Newf("sync: %w, %w",
+			errs.Mark(errors.New("a"), errs.ErrNotFound),
+			errs.Mark(errors.New("b"), errs.ErrOutdated),
+		)
+
+		markers := errs.Markers(err)
+		if len(markers) != 2 {
+			t.Errorf("Markers = %v, want ErrNotFound and ErrOutdated", markers)
+		}
+	})
+
 	t.Run("Newf applies options", func(t *testing.T) {
 		err := errs.Newf("test %s", "msg", func(e *errs.Error) {
 			e.Domain = "domain"
@@ -388,4 +1200,55 @@ func TestExistingFunctionality(t *testing.T) {
 			t.Error("Unwrap() did not return Internal")
 		}
 	})
+
+	t.Run("WithMarkers marks at creation", func(t *testing.T) {
+		err := errs.New("boom", errs.WithMarkers(errs.ErrInternal))
+
+		if !errors.Is(err, errs.ErrInternal) {
+			t.Error("errors.Is(err, ErrInternal) = false, want true")
+		}
+		if got := errs.GetHTTPCode(err); got != 500 {
+			t.Errorf("GetHTTPCode() = %d, want 500", got)
+		}
+
+		notFound := errs.Newf("user %d missing", 7, errs.WithMarkers(errs.ErrNotFound))
+		if got := errs.GetHTTPCode(notFound); got != 404 {
+			t.Errorf("GetHTTPCode() = %d, want 404", got)
+		}
+	})
+
+	t.Run("WithAutoDomain uses the calling function", func(t *testing.T) {
+		err := errs.New("boom", errs.WithAutoDomain()).(*errs.Error)
+
+		want := "errs_test.TestExistingFunctionality.func"
+		if !strings.HasPrefix(err.Domain, want) {
+			t.Errorf("Domain = %q, want prefix %q", err.Domain, want)
+		}
+
+		wrapped := errs.Wrap(errors.New("eof"), "read", errs.WithAutoDomain()).(*errs.Error)
+		if !strings.HasPrefix(wrapped.Domain, want) {
+			t.Errorf("Wrap Domain = %q, want prefix %q", wrapped.Domain, want)
+		}
+	})
+
+	t.Run("WithAutoDomain keeps an existing domain", func(t *testing.T) {
+		base := errs.New("boom", func(e *errs.Error) { e.Domain = "billing" })
+		err := errs.Wrap(base, "charge", errs.WithAutoDomain()).(*errs.Error)
+
+		if err.Domain != "billing" {
+			t.Errorf("Domain = %q, want %q", err.Domain, "billing")
+		}
+	})
+
+	t.Run("WithInternal sets Internal", func(t *testing.T) {
+		base := errors.New("base")
+		err := errs.New("wrapper", errs.WithInternal(base))
+
+		if errors.Unwrap(err) != base {
+			t.Error("Unwrap() did not return Internal")
+		}
+		if !errors.Is(err, base) {
+			t.Error("errors.Is(err, base) = false, want true")
+		}
+	})
 }
@@ -0,0 +1,39 @@
+// Package errsgrpc converts errs errors into gRPC statuses.
+//
+// It is a separate module so the errs package stays free of the grpc
+// dependency.
+package errsgrpc
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/4nd3r5on/errs"
+)
+
+// ToGRPCStatus converts err into a gRPC status with the code of
+// errs.GetGRPCCode and the user-facing message of errs.EffectiveSafeMessage,
+// so the internal message is only sent when the error exposes it.
+// An ErrorInfo detail carries the error's Code, as resolved by errs.Code,
+// as its reason and its Domain.
+// Returns an OK status if err is nil.
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	st := status.New(codes.Code(errs.GetGRPCCode(err)), errs.EffectiveSafeMessage(err))
+	info := &errdetails.ErrorInfo{Reason: errs.Code(err)}
+	var e *errs.Error
+	if errors.As(err, &e) {
+		info.Domain = e.Domain
+	}
+	withInfo, detailErr := st.WithDetails(info)
+	if detailErr != nil {
+		return st
+	}
+	return withInfo
+}
@@ -0,0 +1,71 @@
+package errsgrpc_test
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"github.com/4nd3r5on/errs"
+	"github.com/4nd3r5on/errs/errsgrpc"
+)
+
+func TestToGRPCStatus(t *testing.T) {
+	t.Run("sets code, message and error info", func(t *testing.T) {
+		err := errs.Mark(errors.New("no row for user 7"), errs.ErrNotFound, func(e *errs.Error) {
+			e.Domain = "users"
+		})
+
+		st := errsgrpc.ToGRPCStatus(err)
+
+		if st.Code() != codes.NotFound {
+			t.Errorf("Code() = %v, want %v", st.Code(), codes.NotFound)
+		}
+		if want := "The requested resource was not found"; st.Message() != want {
+			t.Errorf("Message() = %q, want %q", st.Message(), want)
+		}
+		details := st.Details()
+		if len(details) != 1 {
+			t.Fatalf("got %d details, want 1", len(details))
+		}
+		info, ok := details[0].(*errdetails.ErrorInfo)
+		if !ok {
+			t.Fatalf("detail is %T, want *errdetails.ErrorInfo", details[0])
+		}
+		if info.GetReason() != "NOT_FOUND" {
+			t.Errorf("reason = %q, want %q", info.GetReason(), "NOT_FOUND")
+		}
+		if info.GetDomain() != "users" {
+			t.Errorf("domain = %q, want %q", info.GetDomain(), "users")
+		}
+	})
+
+	t.Run("sends the internal message only when exposed", func(t *testing.T) {
+		private := errs.New("dial postgres: connection refused")
+		if got := errsgrpc.ToGRPCStatus(private).Message(); got == private.Error() {
+			t.Errorf("Message() = %q, leaks the internal message", got)
+		}
+
+		exposed := errs.Mark(errors.New("email is taken"), errs.ErrExists, func(e *errs.Error) {
+			e.ExposeInternal = true
+			e.Code = "EMAIL_TAKEN"
+		})
+		st := errsgrpc.ToGRPCStatus(exposed)
+		if st.Message() != "email is taken" {
+			t.Errorf("Message() = %q, want %q", st.Message(), "email is taken")
+		}
+		if st.Code() != codes.AlreadyExists {
+			t.Errorf("Code() = %v, want %v", st.Code(), codes.AlreadyExists)
+		}
+		if info := st.Details()[0].(*errdetails.ErrorInfo); info.GetReason() != "EMAIL_TAKEN" {
+			t.Errorf("reason = %q, want %q", info.GetReason(), "EMAIL_TAKEN")
+		}
+	})
+
+	t.Run("returns OK for nil", func(t *testing.T) {
+		if st := errsgrpc.ToGRPCStatus(nil); st.Code() != codes.OK {
+			t.Errorf("Code() = %v, want %v", st.Code(), codes.OK)
+		}
+	})
+}
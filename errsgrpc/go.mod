@@ -0,0 +1,14 @@
+module github.com/4nd3r5on/errs/errsgrpc
+
+go 1.25.6
+
+require (
+	github.com/4nd3r5on/errs v0.0.0-20261016010910-ec275d36f37f
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	golang.org/x/sys v0.47.0 // indirect
+	google.golang.org/protobuf v1.36.12 // indirect
+)
@@ -0,0 +1,47 @@
+// Package errstest provides test helpers for code using errs.
+package errstest
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+// RequireStatus fails the test immediately if err does not map to the
+// HTTP status want. The failure message includes the resolved status
+// and the canonical name of the matched sentinel.
+func RequireStatus(t testing.TB, err error, want int) {
+	t.Helper()
+	got := errs.GetHTTPCode(err)
+	if got == want {
+		return
+	}
+	name := errs.CanonicalName(err)
+	if name == "" {
+		name = "none"
+	}
+	t.Fatalf("status = %d %s (sentinel %s), want %d %s; err: %v",
+		got, http.StatusText(got), name, want, http.StatusText(want), err)
+}
+
+// AssertNoLeak reports an error for every secret that appears in body,
+// typically a serialized error response.
+func AssertNoLeak(t testing.TB, body []byte, secrets ...string) {
+	t.Helper()
+	for _, secret := range secrets {
+		if secret != "" && bytes.Contains(body, []byte(secret)) {
+			t.Errorf("response leaks %q: %s", secret, body)
+		}
+	}
+}
+
+// SanitizedHandler returns a handler responding to every request with err,
+// as rendered by errs.HandleHTTPErr with opts. Use it with httptest to check
+// the response a client would see, e.g. with AssertNoLeak.
+func SanitizedHandler(err error, opts *errs.HandleHTTPErrOpts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		errs.HandleHTTPErr(r.Context(), w, r, err, opts)
+	})
+}
@@ -0,0 +1,87 @@
+package errstest_test
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+	"github.com/4nd3r5on/errs/errstest"
+)
+
+// fakeTB records failures instead of stopping the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+	msg    string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.failed = true
+	f.msg = fmt.Sprintf(format, args...)
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.failed = true
+	f.msg = fmt.Sprintf(format, args...)
+}
+
+func TestRequireStatus(t *testing.T) {
+	err := errs.Mark(errors.New("no such user"), errs.ErrNotFound)
+
+	t.Run("passes on matching status", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		errstest.RequireStatus(tb, err, http.StatusNotFound)
+
+		if tb.failed {
+			t.Errorf("RequireStatus failed: %s", tb.msg)
+		}
+	})
+
+	t.Run("fails with resolved status and sentinel", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		errstest.RequireStatus(tb, err, http.StatusConflict)
+
+		if !tb.failed {
+			t.Fatal("RequireStatus passed, want failure")
+		}
+		for _, want := range []string{"404", "NOT_FOUND", "409"} {
+			if !strings.Contains(tb.msg, want) {
+				t.Errorf("message = %q, want it to contain %q", tb.msg, want)
+			}
+		}
+	})
+}
+
+func TestAssertNoLeak(t *testing.T) {
+	const secret = "pq: password authentication failed for user admin"
+	err := errs.Wrap(errors.New(secret), "connect db")
+	opts := &errs.HandleHTTPErrOpts{
+		LogOptions: []errs.LogErrOption{errs.LogErrUseLogger(slog.New(slog.DiscardHandler))},
+	}
+
+	t.Run("private error does not leak", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		errstest.SanitizedHandler(err, opts).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		errstest.AssertNoLeak(t, w.Body.Bytes(), secret, "password", "connect db")
+	})
+
+	t.Run("fails on a leaked secret", func(t *testing.T) {
+		tb := &fakeTB{TB: t}
+		errstest.AssertNoLeak(tb, []byte(`{"error":"`+secret+`"}`), "password")
+
+		if !tb.failed {
+			t.Fatal("AssertNoLeak passed, want failure")
+		}
+		if !strings.Contains(tb.msg, "password") {
+			t.Errorf("message = %q, want it to name the secret", tb.msg)
+		}
+	})
+}
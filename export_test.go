@@ -0,0 +1,52 @@
+package errs
+
+import (
+	"slices"
+	"testing"
+)
+
+// ResetThrottles forgets the state of every LogErrUseThrottle key.
+func ResetThrottles() {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	clear(throttles)
+}
+
+// KeepRegistries restores the sentinel registries, HTTPCodeMappings
+// included, to their current state once tb finishes, undoing the
+// registrations of a test.
+func KeepRegistries(tb testing.TB) {
+	aliasMu.RLock()
+	savedAliases := slices.Clone(aliases)
+	aliasMu.RUnlock()
+
+	canonicalMu.RLock()
+	savedMessages := slices.Clone(canonicalMessages)
+	savedNames := slices.Clone(canonicalNames)
+	savedCodes := slices.Clone(registeredCodes)
+	canonicalMu.RUnlock()
+
+	dbMu.RLock()
+	savedDB := slices.Clone(dbMappings)
+	dbMu.RUnlock()
+
+	savedMappings := slices.Clone(HTTPCodeMappings)
+
+	tb.Cleanup(func() {
+		aliasMu.Lock()
+		aliases = savedAliases
+		aliasMu.Unlock()
+
+		canonicalMu.Lock()
+		canonicalMessages = savedMessages
+		canonicalNames = savedNames
+		registeredCodes = savedCodes
+		canonicalMu.Unlock()
+
+		dbMu.Lock()
+		dbMappings = savedDB
+		dbMu.Unlock()
+
+		HTTPCodeMappings = savedMappings
+	})
+}
@@ -10,9 +10,14 @@ type Factory interface {
 	UserMessage(fstr string, args ...any) Factory
 	Logs([]any) Factory
 	Mark(...error) Factory
+	MarkIf(bool, ...error) Factory
 	Private() Factory
 	Public() Factory
+	Expose(bool) Factory
+	IsPrivate() bool
 	Domain(string) Factory
+	Because(reason string) Factory
+	Validate() error
 	Err() error
 }
 
@@ -23,6 +28,7 @@ type factory struct {
 	userDetails any
 	domain      string
 	markers     []error
+	because     string
 
 	private bool  // effective
 	forced  *bool // nil = auto, non-nil = locked
@@ -67,23 +73,39 @@ func (f *factory) Logs(v []any) Factory {
 
 func (f *factory) Mark(errs ...error) Factory {
 	cp := f.clone()
-	cp.markers = append(cp.markers, errs...)
+	for _, m := range errs {
+		if m != nil {
+			cp.markers = append(cp.markers, m)
+		}
+	}
 
 	// If visibility was explicitly forced, do not infer.
 	if cp.forced != nil {
 		return cp
 	}
 
-	for _, e := range errs {
-		code := GetHTTPCode(e)
-		if code < 500 {
-			cp.private = false
+	// Expose only if every marker maps to a client error: a single 5xx
+	// marker keeps the error private, regardless of marking order.
+	private := true
+	for _, m := range cp.markers {
+		if GetHTTPCode(m) >= 500 {
+			private = true
+			break
 		}
+		private = false
 	}
+	cp.private = private
 
 	return cp
 }
 
+func (f *factory) MarkIf(cond bool, errs ...error) Factory {
+	if !cond {
+		return f.clone()
+	}
+	return f.Mark(errs...)
+}
+
 func (f *factory) Private() Factory {
 	cp := f.clone()
 	v := true
@@ -100,24 +122,74 @@ func (f *factory) Public() Factory {
 	return cp
 }
 
+// Expose forces the visibility of the internal message:
+// Expose(true) is Public(), Expose(false) is Private().
+func (f *factory) Expose(expose bool) Factory {
+	if expose {
+		return f.Public()
+	}
+	return f.Private()
+}
+
+// IsPrivate reports the effective visibility the error will be built with.
+func (f *factory) IsPrivate() bool {
+	return f.private
+}
+
 func (f *factory) Domain(d string) Factory {
 	cp := f.clone()
 	cp.domain = d
 	return cp
 }
 
+// Because records why the error was classified the way it is, e.g.
+// "row missing after soft delete". It is logged as the "because" detail,
+// and so appears in the dev debug output, but never in responses.
+func (f *factory) Because(reason string) Factory {
+	cp := f.clone()
+	cp.because = reason
+	return cp
+}
+
+// Validate reports configurations that are likely mistakes.
+// Currently it rejects an explicit Public() combined with a marker that
+// HTTPCodeMappings maps to a 5xx status, which would expose an internal
+// fault to users. Markers without a mapping, such as domain sentinels,
+// are not flagged. Err does not call Validate: an explicit Public() is
+// always honored.
+func (f *factory) Validate() error {
+	if f.forced == nil || *f.forced {
+		return nil
+	}
+	for _, m := range f.markers {
+		if mapping, ok := matchHTTPCodeMapping(m); ok && mapping.Status >= 500 {
+			return fmt.Errorf("public error marked with %q, which maps to status %d", m, mapping.Status)
+		}
+	}
+	return nil
+}
+
+// Err builds the error. It never returns nil and does not modify the
+// factory, so it can be called repeatedly, each call returning a new error.
 func (f *factory) Err() error {
-	if f.internal == nil {
-		f.internal = errors.New("unknown error")
+	internal := f.internal
+	if internal == nil {
+		internal = errors.New("unknown error")
 	}
 
-	return &Error{
-		Internal:       f.internal,
+	e := &Error{
+		Internal:       internal,
 		ExposeInternal: !f.private,
 		SafeMessage:    f.safeMessage,
-		LogDetails:     f.logDetails,
+		LogDetails:     append([]any{}, f.logDetails...),
 		UserDetails:    f.userDetails,
 		Domain:         f.domain,
-		Markers:        f.markers,
+		Markers:        append([]error{}, f.markers...),
+		CreatedAt:      Clock(),
+	}
+	if f.because != "" {
+		e.LogDetails = append(e.LogDetails, "because", f.because)
 	}
+	applyDefaultDomain(e)
+	return e
 }
@@ -0,0 +1,206 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestFactoryMarkIf(t *testing.T) {
+	t.Run("marks when condition is true", func(t *testing.T) {
+		err := errs.F().Message("no such user").MarkIf(true, errs.ErrNotFound).Err()
+
+		if !errors.Is(err, errs.ErrNotFound) {
+			t.Error("errors.Is(err, ErrNotFound) = false, want true")
+		}
+		if asErr := err.(*errs.Error); !asErr.ExposeInternal {
+			t.Error("ExposeInternal = false, want true inferred from 404 marker")
+		}
+	})
+
+	t.Run("does not mark when condition is false", func(t *testing.T) {
+		err := errs.F().Message("no such user").MarkIf(false, errs.ErrNotFound).Err()
+
+		if errors.Is(err, errs.ErrNotFound) {
+			t.Error("errors.Is(err, ErrNotFound) = true, want false")
+		}
+		if asErr := err.(*errs.Error); asErr.ExposeInternal {
+			t.Error("ExposeInternal = true, want false")
+		}
+	})
+}
+
+func TestFactoryValidate(t *testing.T) {
+	t.Run("rejects Public combined with an internal marker", func(t *testing.T) {
+		f := errs.F().Message("cache miss").Mark(errs.ErrInternal).Public()
+
+		if err := f.Validate(); err == nil {
+			t.Error("Validate() = nil, want error")
+		}
+		// The explicit choice is still honored by Err.
+		if asErr := f.Err().(*errs.Error); !asErr.ExposeInternal {
+			t.Error("ExposeInternal = false, want true for explicit Public()")
+		}
+	})
+
+	t.Run("accepts Public with client markers", func(t *testing.T) {
+		f := errs.F().Message("bad id").Mark(errs.ErrInvalidArgument).Public()
+		if err := f.Validate(); err != nil {
+			t.Errorf("Validate() = %v, want nil", err)
+		}
+	})
+
+	t.Run("accepts Public with unmapped domain markers", func(t *testing.T) {
+		errQuotaExceeded := errors.New("quota exceeded")
+		f := errs.F().Message("plan quota exhausted").Public().Mark(errQuotaExceeded)
+		if err := f.Validate(); err != nil {
+			t.Errorf("Validate() = %v, want nil", err)
+		}
+	})
+
+	t.Run("rejects Public with mapped 5xx markers", func(t *testing.T) {
+		f := errs.F().Message("upstream down").Public().Mark(errs.ErrRemoteServiceErr)
+		if err := f.Validate(); err == nil {
+			t.Error("Validate() = nil, want error")
+		}
+	})
+
+	t.Run("accepts internal markers without forced visibility", func(t *testing.T) {
+		f := errs.F().Message("cache miss").Mark(errs.ErrInternal)
+		if err := f.Validate(); err != nil {
+			t.Errorf("Validate() = %v, want nil", err)
+		}
+	})
+}
+
+func TestFactoryExpose(t *testing.T) {
+	t.Run("Expose(true) exposes the internal message", func(t *testing.T) {
+		f := errs.F().Message("cache warming").Expose(true)
+
+		if f.IsPrivate() {
+			t.Error("IsPrivate() = true, want false")
+		}
+		if asErr := f.Err().(*errs.Error); !asErr.ExposeInternal {
+			t.Error("ExposeInternal = false, want true")
+		}
+	})
+
+	t.Run("Expose(false) is locked against inference", func(t *testing.T) {
+		f := errs.F().Message("bad id").Expose(false).Mark(errs.ErrInvalidArgument)
+
+		if !f.IsPrivate() {
+			t.Error("IsPrivate() = false, want true")
+		}
+		if asErr := f.Err().(*errs.Error); asErr.ExposeInternal {
+			t.Error("ExposeInternal = true, want false")
+		}
+	})
+
+	t.Run("IsPrivate reflects inference", func(t *testing.T) {
+		f := errs.F().Message("bad id")
+		if !f.IsPrivate() {
+			t.Error("IsPrivate() = false by default, want true")
+		}
+		if f.Mark(errs.ErrInvalidArgument).IsPrivate() {
+			t.Error("IsPrivate() = true after 400 marker, want false")
+		}
+	})
+}
+
+func TestFactoryMarkInference(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory errs.Factory
+	}{
+		{"client then internal", errs.F().Mark(errs.ErrNotFound, errs.ErrInternal)},
+		{"internal then client", errs.F().Mark(errs.ErrInternal, errs.ErrNotFound)},
+		{"separate Mark calls", errs.F().Mark(errs.ErrInternal).Mark(errs.ErrNotFound)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.factory.Message("user 7 lookup failed")
+			if !f.IsPrivate() {
+				t.Error("IsPrivate() = false, want true")
+			}
+			if asErr := f.Err().(*errs.Error); asErr.ExposeInternal {
+				t.Error("ExposeInternal = true, want false")
+			}
+		})
+	}
+
+	t.Run("Public overrides inference", func(t *testing.T) {
+		f := errs.F().Public().Mark(errs.ErrNotFound, errs.ErrInternal)
+		if f.IsPrivate() {
+			t.Error("IsPrivate() = true, want false")
+		}
+	})
+}
+
+func TestFactoryErrIsRepeatable(t *testing.T) {
+	f := errs.F().Mark(errs.ErrNotFound)
+
+	first := f.Err().(*errs.Error)
+	second := f.Err().(*errs.Error)
+
+	if first == second {
+		t.Fatal("Err() returned the same error twice")
+	}
+	if first.Error() != "unknown error" || second.Error() != "unknown error" {
+		t.Errorf("messages = %q, %q, want default message", first, second)
+	}
+	if first.Internal == second.Internal {
+		t.Error("errors share the default Internal")
+	}
+
+	first.Markers[0] = errs.ErrExists
+	first.LogDetails = append(first.LogDetails, "k", "v")
+	if !errors.Is(second, errs.ErrNotFound) {
+		t.Error("mutating the first error changed the second")
+	}
+
+	branched := f.Message("no such user").Err()
+	if branched.Error() != "no such user" {
+		t.Errorf("branched Error() = %q, want %q", branched.Error(), "no such user")
+	}
+	if third := f.Err(); third.Error() != "unknown error" || errors.Is(third, errs.ErrExists) {
+		t.Errorf("factory changed after Err(): %+v", third)
+	}
+}
+
+func TestFactoryBecause(t *testing.T) {
+	const reason = "row missing after soft delete"
+	err := errs.F().Message("user 7 not found").Mark(errs.ErrNotFound).Because(reason).Err()
+
+	t.Run("logs the reason", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		errs.LogErr(context.Background(), err, errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		details, _ := entries[0]["error_details"].(map[string]any)
+		if details["because"] != reason {
+			t.Errorf("because = %v, want %q", details["because"], reason)
+		}
+		if entries[0]["msg"] != "user 7 not found" {
+			t.Errorf("msg = %v, want the internal message", entries[0]["msg"])
+		}
+	})
+
+	t.Run("keeps the reason out of responses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		if strings.Contains(w.Body.String(), reason) {
+			t.Errorf("response leaks reason: %s", w.Body.String())
+		}
+	})
+}
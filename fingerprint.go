@@ -0,0 +1,39 @@
+package errs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+)
+
+var (
+	uuidPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// Fingerprint returns a short stable hash grouping errors that differ only
+// in embedded identifiers. It covers the message with UUIDs and numbers
+// masked, the Domain and the canonical sentinel.
+// Returns an empty string if err is nil.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	msg := uuidPattern.ReplaceAllString(err.Error(), "<uuid>")
+	msg = numberPattern.ReplaceAllString(msg, "<n>")
+
+	var domain string
+	var e *Error
+	if errors.As(err, &e) {
+		domain = e.Domain
+	}
+
+	h := sha256.New()
+	for _, part := range []string{msg, domain, CanonicalName(err)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
@@ -0,0 +1,47 @@
+package errs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestFingerprint(t *testing.T) {
+	newErr := func(msg string) error {
+		return errs.Mark(errors.New(msg), errs.ErrNotFound, func(e *errs.Error) { e.Domain = "users" })
+	}
+
+	t.Run("ignores embedded ids", func(t *testing.T) {
+		a := errs.Fingerprint(newErr("user 42 not found in org 3f2504e0-4f89-11d3-9a0c-0305e82c3301"))
+		b := errs.Fingerprint(newErr("user 7 not found in org 6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+
+		if a != b {
+			t.Errorf("fingerprints differ: %s != %s", a, b)
+		}
+		if len(a) != 16 {
+			t.Errorf("len(fingerprint) = %d, want 16", len(a))
+		}
+	})
+
+	t.Run("differs by message, domain and sentinel", func(t *testing.T) {
+		base := errs.Fingerprint(newErr("user 42 not found"))
+
+		others := map[string]error{
+			"message":  newErr("user 42 disabled"),
+			"domain":   errs.Mark(errors.New("user 42 not found"), errs.ErrNotFound),
+			"sentinel": errs.Mark(errors.New("user 42 not found"), errs.ErrExists, func(e *errs.Error) { e.Domain = "users" }),
+		}
+		for name, err := range others {
+			if errs.Fingerprint(err) == base {
+				t.Errorf("fingerprint unchanged for different %s", name)
+			}
+		}
+	})
+
+	t.Run("returns empty string for nil", func(t *testing.T) {
+		if got := errs.Fingerprint(nil); got != "" {
+			t.Errorf("Fingerprint(nil) = %q, want empty", got)
+		}
+	})
+}
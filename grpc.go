@@ -0,0 +1,58 @@
+package errs
+
+import (
+	"errors"
+)
+
+// gRPC status codes, numerically identical to google.golang.org/grpc/codes.
+// They are duplicated here to keep the package free of the grpc dependency.
+const (
+	grpcOK                 uint32 = 0
+	grpcInvalidArgument    uint32 = 3
+	grpcDeadlineExceeded   uint32 = 4
+	grpcNotFound           uint32 = 5
+	grpcAlreadyExists      uint32 = 6
+	grpcPermissionDenied   uint32 = 7
+	grpcResourceExhausted  uint32 = 8
+	grpcFailedPrecondition uint32 = 9
+	grpcOutOfRange         uint32 = 11
+	grpcUnimplemented      uint32 = 12
+	grpcInternal           uint32 = 13
+	grpcUnavailable        uint32 = 14
+	grpcUnauthenticated    uint32 = 16
+)
+
+// GetGRPCCode maps err to a gRPC status code.
+// The result can be converted directly with codes.Code(GetGRPCCode(err)).
+func GetGRPCCode(err error) uint32 {
+	switch {
+	case err == nil:
+		return grpcOK
+	case errors.Is(err, ErrInternal):
+		return grpcInternal
+	case errors.Is(err, ErrNotImplemented):
+		return grpcUnimplemented
+	case errors.Is(err, ErrDeadlineExceeded):
+		return grpcDeadlineExceeded
+	case errors.Is(err, ErrRemoteServiceErr):
+		return grpcUnavailable
+	case errors.Is(err, ErrRateLimited):
+		return grpcResourceExhausted
+	case IsAny(err, ErrInvalidArgument, ErrMissingArgument):
+		return grpcInvalidArgument
+	case errors.Is(err, ErrOutOfRange):
+		return grpcOutOfRange
+	case errors.Is(err, ErrPermissionDenied):
+		return grpcPermissionDenied
+	case errors.Is(err, ErrUnauthorized):
+		return grpcUnauthenticated
+	case errors.Is(err, ErrExists):
+		return grpcAlreadyExists
+	case errors.Is(err, ErrOutdated):
+		return grpcFailedPrecondition
+	case errors.Is(err, ErrNotFound):
+		return grpcNotFound
+	default:
+		return grpcInternal
+	}
+}
@@ -0,0 +1,32 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestGetGRPCCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want uint32
+	}{
+		{"nil", nil, 0},
+		{"not found marker", errs.Mark(errors.New("no row"), errs.ErrNotFound), 5},
+		{"wrapped invalid argument", errs.Wrap(errs.ErrInvalidArgument, "parse id"), 3},
+		{"unauthorized", errs.ErrUnauthorized, 16},
+		{"deadline", context.DeadlineExceeded, 4},
+		{"foreign", errors.New("boom"), 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.GetGRPCCode(tt.err); got != tt.want {
+				t.Errorf("GetGRPCCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package errs
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
 )
 
 func IsAny(err error, references ...error) bool {
@@ -12,3 +17,218 @@ func IsAny(err error, references ...error) bool {
 	}
 	return false
 }
+
+// As finds the first error in err's chain that matches type T, like
+// errors.As. Only the Internal chain of *Error is searched; markers are
+// sentinels for errors.Is and never match.
+func As[T error](err error) (T, bool) {
+	var target T
+	ok := errors.As(err, &target)
+	return target, ok
+}
+
+// IsClientError reports whether err maps to a 4xx status.
+func IsClientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := GetHTTPCode(err)
+	return code >= 400 && code < 500
+}
+
+// IsServerError reports whether err maps to a 5xx status.
+func IsServerError(err error) bool {
+	return err != nil && GetHTTPCode(err) >= 500
+}
+
+// IsNotFound reports whether err matches ErrNotFound, through markers too.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsPermissionDenied reports whether err matches ErrPermissionDenied.
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied)
+}
+
+// IsUnauthorized reports whether err matches ErrUnauthorized.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsInvalidArgument reports whether err matches ErrInvalidArgument.
+func IsInvalidArgument(err error) bool {
+	return errors.Is(err, ErrInvalidArgument)
+}
+
+// IsExists reports whether err matches ErrExists.
+func IsExists(err error) bool {
+	return errors.Is(err, ErrExists)
+}
+
+// IsRateLimited reports whether err matches ErrRateLimited.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// ShouldTripBreaker reports whether a circuit breaker should count err as
+// a failure: true for server-side and transient errors (5xx, ErrRemoteServiceErr,
+// ErrDeadlineExceeded), false for client errors and nil.
+func ShouldTripBreaker(err error) bool {
+	if err == nil {
+		return false
+	}
+	return IsAny(err, ErrRemoteServiceErr, ErrDeadlineExceeded) || IsServerError(err)
+}
+
+// FirstError returns the most severe non-nil error among errs.
+// Severity is the status reported by GetHTTPCode: a higher status is more
+// severe, so any 5xx outranks any 4xx. Ties go to the earliest error.
+// Returns nil if all errors are nil.
+func FirstError(errs ...error) error {
+	var (
+		worst       error
+		worstStatus int
+	)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if status := GetHTTPCode(err); worst == nil || status > worstStatus {
+			worst, worstStatus = err, status
+		}
+	}
+	return worst
+}
+
+// IsExposable reports whether the internal message of err may be shown to
+// the end user. It is true when the nearest *Error in the chain has
+// ExposeInternal set or maps to a client error status (< 500).
+// Errors that are not *Error are never exposable.
+func IsExposable(err error) bool {
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.ExposeInternal || GetHTTPCode(e) < 500
+}
+
+// Markers returns a copy of the markers attached to the nearest *Error
+// in the chain of err. Returns nil if there is none.
+func Markers(err error) []error {
+	var e *Error
+	if !errors.As(err, &e) || len(e.Markers) == 0 {
+		return nil
+	}
+	return append([]error{}, e.Markers...)
+}
+
+// CompactMessage returns the message of err keeping only the outermost
+// maxLayers wrap contexts, followed by an ellipsis and the root cause:
+//
+//	"handler: service: ...: connection refused"
+//
+// Layers are detected on both Wrap and fmt.Errorf("...: %w") chains.
+// The full message is returned if it has no more than maxLayers contexts.
+func CompactMessage(err error, maxLayers int) string {
+	if err == nil {
+		return ""
+	}
+
+	var contexts []string
+	root := err.Error()
+	for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(inner) {
+		innerMsg := inner.Error()
+		if innerMsg == root {
+			// *Error and its Internal share a message
+			continue
+		}
+		ctx, ok := strings.CutSuffix(root, ": "+innerMsg)
+		if !ok {
+			break
+		}
+		contexts = append(contexts, ctx)
+		root = innerMsg
+	}
+
+	if len(contexts) <= maxLayers {
+		return err.Error()
+	}
+	kept := append([]string{}, contexts[:max(maxLayers, 0)]...)
+	kept = append(kept, "...", root)
+	return strings.Join(kept, ": ")
+}
+
+// Walk calls fn for err and every error reachable from it through
+// Unwrap() error and Unwrap() []error, depth first, outermost first.
+// Markers are not visited. Walking stops as soon as fn returns false.
+func Walk(err error, fn func(error) bool) {
+	walk(err, fn)
+}
+
+func walk(err error, fn func(error) bool) bool {
+	if err == nil {
+		return true
+	}
+	if !fn(err) {
+		return false
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			if !walk(inner, fn) {
+				return false
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walk(u.Unwrap(), fn)
+	}
+	return true
+}
+
+// SafeCall runs fn and returns its error. A panic in fn is recovered into
+// an *Error marked ErrInternal, with the panic value in LogDetails under
+// "panic" and the stack captured at the point of recovery.
+// Panic values that are errors stay reachable through errors.Is/As.
+func SafeCall(fn func() error) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		var internal error
+		if rErr, ok := r.(error); ok {
+			internal = fmt.Errorf("panic: %w", rErr)
+		} else {
+			internal = fmt.Errorf("panic: %v", r)
+		}
+		err = &Error{
+			Internal:   internal,
+			LogDetails: []any{"panic", r},
+			Markers:    []error{ErrInternal},
+			Stack:      debug.Stack(),
+			CreatedAt:  Clock(),
+		}
+	}()
+	return fn()
+}
+
+// WithTimeout runs fn with a context derived from ctx that expires after d.
+// If fn fails once the context deadline passed, its error is wrapped and
+// marked ErrDeadlineExceeded, so it maps to 504. Errors returned before the
+// deadline are returned as is.
+func WithTimeout(ctx context.Context, d time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	err := fn(ctx)
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return Wrap(err, fmt.Sprintf("timed out after %s", d), WithMarkers(ErrDeadlineExceeded))
+}
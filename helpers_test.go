@@ -0,0 +1,352 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestFirstError(t *testing.T) {
+	t.Run("returns nil when all errors are nil", func(t *testing.T) {
+		if got := errs.FirstError(nil, nil); got != nil {
+			t.Errorf("FirstError(nil, nil) = %v, want nil", got)
+		}
+	})
+
+	t.Run("selects 5xx over 4xx", func(t *testing.T) {
+		notFound := errs.Mark(errors.New("no such user"), errs.ErrNotFound)
+		internal := errors.New("db connection reset")
+
+		got := errs.FirstError(nil, notFound, internal)
+		if got != internal {
+			t.Errorf("FirstError() = %v, want %v", got, internal)
+		}
+	})
+
+	t.Run("keeps the earliest error on ties", func(t *testing.T) {
+		first := errs.Mark(errors.New("first"), errs.ErrNotFound)
+		second := errs.Mark(errors.New("second"), errs.ErrNotFound)
+
+		if got := errs.FirstError(first, second); got != first {
+			t.Errorf("FirstError() = %v, want %v", got, first)
+		}
+	})
+}
+
+func TestIsExposable(t *testing.T) {
+	t.Run("private 500 is not exposable", func(t *testing.T) {
+		err := errs.F().Message("db password rejected").Err()
+		if errs.IsExposable(err) {
+			t.Error("IsExposable(private 500) = true, want false")
+		}
+	})
+
+	t.Run("public 400 is exposable", func(t *testing.T) {
+		err := errs.F().Message("age must be positive").Mark(errs.ErrInvalidArgument).Err()
+		if !errs.IsExposable(err) {
+			t.Error("IsExposable(public 400) = false, want true")
+		}
+		if !errs.IsExposable(errs.Wrap(err, "validate request")) {
+			t.Error("IsExposable(wrapped public 400) = false, want true")
+		}
+	})
+
+	t.Run("foreign error is not exposable", func(t *testing.T) {
+		if errs.IsExposable(errors.New("boom")) {
+			t.Error("IsExposable(foreign) = true, want false")
+		}
+		if errs.IsExposable(errs.ErrNotFound) {
+			t.Error("IsExposable(bare sentinel) = true, want false")
+		}
+	})
+}
+
+func TestCompactMessage(t *testing.T) {
+	root := errors.New("connection refused")
+	var err error = errs.Mark(root, errs.ErrRemoteServiceErr)
+	err = errs.Wrap(err, "dial db")
+	err = fmt.Errorf("query users: %w", err)
+	err = errs.Wrap(err, "load profile")
+	err = fmt.Errorf("service: %w", err)
+	err = errs.Wrap(err, "handler")
+
+	t.Run("collapses middle layers", func(t *testing.T) {
+		want := "handler: service: ...: connection refused"
+		if got := errs.CompactMessage(err, 2); got != want {
+			t.Errorf("CompactMessage(err, 2) = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("keeps short chains intact", func(t *testing.T) {
+		if got := errs.CompactMessage(err, 5); got != err.Error() {
+			t.Errorf("CompactMessage(err, 5) = %q, want %q", got, err.Error())
+		}
+	})
+
+	t.Run("zero layers keeps only the root cause", func(t *testing.T) {
+		want := "...: connection refused"
+		if got := errs.CompactMessage(err, 0); got != want {
+			t.Errorf("CompactMessage(err, 0) = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("returns empty string for nil", func(t *testing.T) {
+		if got := errs.CompactMessage(nil, 2); got != "" {
+			t.Errorf("CompactMessage(nil, 2) = %q, want empty", got)
+		}
+	})
+}
+
+func TestWalk(t *testing.T) {
+	root := errors.New("root")
+	marked := errs.Mark(root, errs.ErrNotFound)
+	wrapped := errs.Wrap(marked, "load") // *Error -> fmt wrap -> marked -> root
+	other := errors.New("other")
+	joined := errors.Join(wrapped, other)
+
+	t.Run("visits every node of the graph", func(t *testing.T) {
+		var visited []error
+		errs.Walk(joined, func(err error) bool {
+			visited = append(visited, err)
+			return true
+		})
+
+		if len(visited) != 6 {
+			t.Fatalf("visited %d nodes, want 6: %v", len(visited), visited)
+		}
+		if visited[0] != joined || visited[1] != wrapped || visited[5] != other {
+			t.Errorf("unexpected visiting order: %v", visited)
+		}
+	})
+
+	t.Run("stops when fn returns false", func(t *testing.T) {
+		count := 0
+		errs.Walk(joined, func(err error) bool {
+			count++
+			return err != marked
+		})
+
+		if count != 4 {
+			t.Errorf("visited %d nodes, want 4", count)
+		}
+	})
+
+	t.Run("does nothing for nil", func(t *testing.T) {
+		errs.Walk(nil, func(error) bool {
+			t.Error("fn called for nil error")
+			return true
+		})
+	})
+}
+
+func TestIsClientServerError(t *testing.T) {
+	notFound := errs.Mark(errors.New("no row"), errs.ErrNotFound)
+	internal := errs.Mark(errors.New("nil map"), errs.ErrInternal)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantClient bool
+		wantServer bool
+	}{
+		{"nil", nil, false, false},
+		{"marked 404", notFound, true, false},
+		{"wrapped 404", fmt.Errorf("handler: %w", errs.Wrap(notFound, "load")), true, false},
+		{"internal", internal, false, true},
+		{"wrapped internal", errs.Wrap(internal, "save"), false, true},
+		{"foreign", errors.New("boom"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.IsClientError(tt.err); got != tt.wantClient {
+				t.Errorf("IsClientError() = %v, want %v", got, tt.wantClient)
+			}
+			if got := errs.IsServerError(tt.err); got != tt.wantServer {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.wantServer)
+			}
+		})
+	}
+}
+
+func TestSentinelPredicates(t *testing.T) {
+	notFound := errs.Mark(errors.New("no row"), errs.ErrNotFound)
+	limited := errs.New("quota", errs.WithMarkers(errs.ErrRateLimited))
+
+	tests := []struct {
+		name string
+		fn   func(error) bool
+		err  error
+		want bool
+	}{
+		{"IsNotFound marked", errs.IsNotFound, notFound, true},
+		{"IsNotFound wrapped", errs.IsNotFound, fmt.Errorf("handler: %w", errs.Wrap(notFound, "load")), true},
+		{"IsNotFound sentinel", errs.IsNotFound, errs.ErrNotFound, true},
+		{"IsNotFound other", errs.IsNotFound, limited, false},
+		{"IsNotFound nil", errs.IsNotFound, nil, false},
+		{"IsRateLimited marked", errs.IsRateLimited, limited, true},
+		{"IsRateLimited wrapped", errs.IsRateLimited, errs.Wrap(limited, "upload"), true},
+		{"IsPermissionDenied", errs.IsPermissionDenied, errs.Mark(errors.New("x"), errs.ErrPermissionDenied), true},
+		{"IsUnauthorized", errs.IsUnauthorized, errs.Mark(errors.New("x"), errs.ErrUnauthorized), true},
+		{"IsInvalidArgument", errs.IsInvalidArgument, errs.Mark(errors.New("x"), errs.ErrInvalidArgument), true},
+		{"IsExists", errs.IsExists, errs.Mark(errors.New("x"), errs.ErrExists), true},
+		{"IsExists other", errs.IsExists, notFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.err); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeCall(t *testing.T) {
+	t.Run("returns nil on normal return", func(t *testing.T) {
+		if err := errs.SafeCall(func() error { return nil }); err != nil {
+			t.Errorf("SafeCall() = %v, want nil", err)
+		}
+	})
+
+	t.Run("returns fn's error unchanged", func(t *testing.T) {
+		want := errors.New("boom")
+		if err := errs.SafeCall(func() error { return want }); err != want {
+			t.Errorf("SafeCall() = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("converts panic into internal error", func(t *testing.T) {
+		err := errs.SafeCall(func() error { panic("bad state") })
+
+		var e *errs.Error
+		if !errors.As(err, &e) {
+			t.Fatalf("SafeCall() = %T, want *errs.Error", err)
+		}
+		if !errors.Is(err, errs.ErrInternal) {
+			t.Error("errors.Is(err, ErrInternal) = false, want true")
+		}
+		if len(e.LogDetails) != 2 || e.LogDetails[0] != "panic" || e.LogDetails[1] != "bad state" {
+			t.Errorf("LogDetails = %v, want [panic bad state]", e.LogDetails)
+		}
+		if len(e.Stack) == 0 {
+			t.Error("Stack is empty, want captured stack")
+		}
+		if got := errs.GetHTTPCode(err); got != 500 {
+			t.Errorf("GetHTTPCode() = %d, want 500", got)
+		}
+	})
+
+	t.Run("keeps panicked error in chain", func(t *testing.T) {
+		cause := errors.New("cause")
+		err := errs.SafeCall(func() error { panic(cause) })
+
+		if !errors.Is(err, cause) {
+			t.Error("errors.Is(err, cause) = false, want true")
+		}
+	})
+}
+
+func TestWithTimeout(t *testing.T) {
+	t.Run("marks deadline errors", func(t *testing.T) {
+		err := errs.WithTimeout(context.Background(), time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		if !errors.Is(err, errs.ErrDeadlineExceeded) {
+			t.Error("errors.Is(err, ErrDeadlineExceeded) = false, want true")
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Error("errors.Is(err, context.DeadlineExceeded) = false, want true")
+		}
+		if got := errs.GetHTTPCode(err); got != http.StatusGatewayTimeout {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusGatewayTimeout)
+		}
+	})
+
+	t.Run("returns fast errors as is", func(t *testing.T) {
+		want := errors.New("bad input")
+		err := errs.WithTimeout(context.Background(), time.Minute, func(context.Context) error {
+			return want
+		})
+
+		if err != want {
+			t.Errorf("WithTimeout() = %v, want %v", err, want)
+		}
+	})
+
+	t.Run("returns nil on fast completion", func(t *testing.T) {
+		err := errs.WithTimeout(context.Background(), time.Minute, func(context.Context) error {
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("WithTimeout() = %v, want nil", err)
+		}
+	})
+}
+
+type opError struct{ op string }
+
+func (e *opError) Error() string { return e.op + ": connection refused" }
+
+func TestAs(t *testing.T) {
+	t.Run("extracts a foreign type from a marked and wrapped chain", func(t *testing.T) {
+		cause := &opError{op: "dial"}
+		err := errs.Wrap(errs.Mark(cause, errs.ErrRemoteServiceErr), "fetch profile")
+		err = fmt.Errorf("handler: %w", err)
+
+		got, ok := errs.As[*opError](err)
+		if !ok {
+			t.Fatal("As[*opError]() ok = false, want true")
+		}
+		if got != cause {
+			t.Errorf("As[*opError]() = %v, want %v", got, cause)
+		}
+	})
+
+	t.Run("ignores markers", func(t *testing.T) {
+		cause := &opError{op: "dial"}
+		err := errs.Mark(cause, &opError{op: "marker"})
+
+		if got, _ := errs.As[*opError](err); got != cause {
+			t.Errorf("As[*opError]() = %v, want the chain's %v", got, cause)
+		}
+	})
+
+	t.Run("reports false when no error matches", func(t *testing.T) {
+		got, ok := errs.As[*opError](errors.New("boom"))
+		if ok || got != nil {
+			t.Errorf("As[*opError]() = %v, %v, want nil, false", got, ok)
+		}
+	})
+}
+
+func TestShouldTripBreaker(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad request", errs.Mark(errors.New("bad id"), errs.ErrInvalidArgument), false},
+		{"not found", errs.Mark(errors.New("no row"), errs.ErrNotFound), false},
+		{"upstream failure", errs.Mark(errors.New("503 from billing"), errs.ErrRemoteServiceErr), true},
+		{"internal", errors.New("db connection reset"), true},
+		{"timeout", fmt.Errorf("query: %w", context.DeadlineExceeded), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.ShouldTripBreaker(tt.err); got != tt.want {
+				t.Errorf("ShouldTripBreaker() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
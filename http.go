@@ -5,37 +5,92 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"net/http"
+	"slices"
 )
 
+// DevMode makes HandleHTTPErr respond with the full internal message and
+// captured stack of every error, including private ones.
+// It must stay disabled in production.
+var DevMode = false
+
+// HTTPCodeMapping maps errors matching Sentinel to an HTTP status.
+type HTTPCodeMapping struct {
+	Sentinel error
+	Status   int
+}
+
+// HTTPCodeMappings is walked in order by GetHTTPCode and the first mapping
+// whose sentinel the error matches wins. Order therefore defines precedence
+// for errors carrying several markers. It is not safe to modify concurrently
+// with GetHTTPCode, so customize it during initialization.
+var HTTPCodeMappings = []HTTPCodeMapping{
+	{ErrInternal, http.StatusInternalServerError},
+	{ErrNotImplemented, http.StatusNotImplemented},
+	{ErrDeadlineExceeded, http.StatusGatewayTimeout},
+	{ErrRemoteServiceErr, http.StatusBadGateway},
+	{ErrRateLimited, http.StatusTooManyRequests},
+	{ErrInvalidArgument, http.StatusBadRequest},
+	{ErrMissingArgument, http.StatusBadRequest},
+	{ErrOutOfRange, http.StatusBadRequest},
+	{ErrPermissionDenied, http.StatusForbidden},
+	{ErrUnauthorized, http.StatusUnauthorized},
+	{ErrExists, http.StatusConflict},
+	{ErrOutdated, http.StatusConflict},
+	{ErrNotFound, http.StatusNotFound},
+}
+
+// GetHTTPCode returns the status of the first entry in HTTPCodeMappings
+// that err matches, or 500 if none does.
 func GetHTTPCode(err error) int {
-	switch {
-	case errors.Is(err, ErrNotImplemented):
-		return http.StatusNotImplemented
-	case errors.Is(err, context.DeadlineExceeded):
-		return http.StatusGatewayTimeout
-	case errors.Is(err, ErrRemoteServiceErr):
-		return http.StatusBadGateway
-	case errors.Is(err, ErrRateLimited):
-		return http.StatusTooManyRequests
-	case IsAny(err,
-		ErrInvalidArgument,
-		ErrMissingArgument,
-		ErrOutOfRange,
-	):
-		return http.StatusBadRequest
-	case errors.Is(err, ErrPermissionDenied):
-		return http.StatusForbidden
-	case errors.Is(err, ErrUnauthorized):
-		return http.StatusUnauthorized
-	case IsAny(err, ErrExists, ErrOutdated):
-		return http.StatusConflict
-	case errors.Is(err, ErrNotFound):
-		return http.StatusNotFound
-	default:
-		return http.StatusInternalServerError
+	if m, ok := matchHTTPCodeMapping(err); ok {
+		return m.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// MarkFromStatus marks err with the sentinel mapped to status, e.g. to
+// translate the status of an upstream response: 404 marks ErrNotFound,
+// 409 marks ErrExists. The first entry of HTTPCodeMappings with the status
+// is used; statuses without a mapping mark ErrInternal.
+// Returns nil if err is nil.
+func MarkFromStatus(err error, status int) error {
+	return Mark(err, sentinelForStatus(status))
+}
+
+func sentinelForStatus(status int) error {
+	if sentinel, ok := mappedSentinel(status); ok {
+		return sentinel
+	}
+	return ErrInternal
+}
+
+// mappedSentinel returns the sentinel of the first entry of
+// HTTPCodeMappings with status.
+func mappedSentinel(status int) (error, bool) {
+	for _, m := range HTTPCodeMappings {
+		if m.Status == status {
+			return m.Sentinel, true
+		}
+	}
+	return nil, false
+}
+
+// StatusText returns the HTTP status text of the status err maps to.
+func StatusText(err error) string {
+	return http.StatusText(GetHTTPCode(err))
+}
+
+func matchHTTPCodeMapping(err error) (HTTPCodeMapping, bool) {
+	for _, m := range HTTPCodeMappings {
+		if errors.Is(err, m.Sentinel) {
+			return m, true
+		}
 	}
+	return HTTPCodeMapping{}, false
 }
 
 func HTTPGetLogLevel(status int) slog.Level {
@@ -50,28 +105,192 @@ func HTTPGetLogLevel(status int) slog.Level {
 }
 
 type ErrorHTTPResponse struct {
-	Error   string `json:"error"`
-	Details any    `json:"details,omitempty"`
+	Error     string     `json:"error"`
+	Code      string     `json:"code,omitempty"`
+	Details   any        `json:"details,omitempty"`
+	Hints     []string   `json:"hints,omitempty"`
+	ErrorInfo *ErrorInfo `json:"error_info,omitempty"`
+
+	// Stack is only populated in DevMode.
+	Stack string `json:"stack,omitempty"`
+
+	// Debug is only populated in DevMode with HandleHTTPErrOpts.IncludeDebug.
+	Debug *ErrorDebug `json:"debug,omitempty"`
+
+	// Extra holds additional top-level fields, such as a request ID, set by
+	// HandleHTTPErrOpts.ResponseTransformer. Keys are never renamed by
+	// FieldNaming, and keys of the fields above are ignored.
+	Extra map[string]any `json:"-"`
+}
+
+// ErrorDebug exposes the internals of an error for local debugging.
+type ErrorDebug struct {
+	// Chain holds the message of every error in the unwrap tree,
+	// starting with the handled error.
+	Chain      []string `json:"chain"`
+	Stack      string   `json:"stack,omitempty"`
+	LogDetails []any    `json:"log_details,omitempty"`
+}
+
+// ErrorInfo identifies the error in the shape of google.rpc.ErrorInfo.
+type ErrorInfo struct {
+	Reason   string            `json:"reason,omitempty"`
+	Domain   string            `json:"domain,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// HandleHTTPErrOpts configures HandleHTTPErr.
+type HandleHTTPErrOpts struct {
+	// LogOptions are applied on top of the status-derived log level.
+	LogOptions []LogErrOption
+
+	// IncludeErrorCode adds the "code", as resolved by Code, and the
+	// "error_info" object to JSON responses.
+	IncludeErrorCode bool
+
+	// FieldNaming selects the casing of the response body keys
+	// for the default JSON renderer.
+	FieldNaming FieldNaming
+
+	// FieldNames renames individual keys of the response body for the
+	// default JSON renderer, e.g. "error" to "message" for a gateway.
+	FieldNames ResponseFieldNames
+
+	// Renderer writes the response. Defaults to JSONRenderer.
+	Renderer ResponseRenderer
+
+	// Localizer, if set, translates hints into the language of the request's
+	// Accept-Language header. Hints are then treated as message keys.
+	Localizer Localizer
+
+	// MaxResponseBytes limits the size of the JSON encoded body. Larger bodies
+	// are sent without details, or with only the error message if that is not
+	// enough, and the truncation is logged. Zero means no limit.
+	MaxResponseBytes int
+
+	// IncludeMeta sends the error's Metadata as HTTP trailers, in key order.
+	IncludeMeta bool
+
+	// StatusOverride, if set, remaps the status resolved by GetHTTPCode,
+	// e.g. to turn 403 into 404 and not reveal that a resource exists.
+	// When the status changes, the response message and code are those of
+	// the new status, and details, hints and error info are omitted, so the
+	// body does not disclose the original one.
+	StatusOverride func(err error, defaultStatus int) int
+
+	// MarkerHandlers run custom logic for errors matching a marker, e.g. to
+	// redirect on ErrUnauthorized. Each handler whose marker matches the error
+	// (errors.Is) is called before the default rendering, in the stable marker
+	// order used by %+v. The first handler writing to the response
+	// short-circuits the remaining handlers and the default rendering.
+	// The error is logged either way.
+	MarkerHandlers map[error]func(http.ResponseWriter, *http.Request, error)
+
+	// IncludeDebug adds the "debug" object with the unwrap chain, stack and
+	// log details to responses. It has no effect unless DevMode is enabled.
+	IncludeDebug bool
+
+	// RecordSpanError, if set, is called with every handled error and its
+	// status, e.g. to record it on the active OpenTelemetry span.
+	RecordSpanError func(ctx context.Context, err error, status int)
+
+	// ResponseTransformer, if set, is called with the response body before
+	// it is rendered, e.g. to add a request ID to Extra.
+	ResponseTransformer func(ctx context.Context, resp *ErrorHTTPResponse)
+
+	// StoreInContext records the handled error in the slot reserved with
+	// WithError on ctx, or else on the request context, for middleware
+	// reading it with ErrorFromContext, e.g. for access logs.
+	StoreInContext bool
+}
+
+// handledHeader marks a response already written by HandleHTTPErr when
+// neither a BufferedResponseWriter nor a context slot reserved with
+// WithError can record it. It is set only once a status was written, so it
+// never reaches the client.
+const handledHeader = "X-Errs-Handled"
+
+// FieldNaming selects the casing of the keys of ErrorHTTPResponse, including
+// those of its ErrorInfo and Debug objects. Keys inside Details and
+// ErrorInfo.Metadata are user data and are never renamed.
+type FieldNaming int
+
+const (
+	// FieldNamingSnake uses snake_case keys, e.g. "error_info". It is the default.
+	FieldNamingSnake FieldNaming = iota
+	// FieldNamingCamel uses camelCase keys, e.g. "errorInfo".
+	FieldNamingCamel
+)
+
+// ResponseFieldNames overrides keys of the JSON response body.
+// Empty fields keep the key selected by FieldNaming.
+type ResponseFieldNames struct {
+	Error   string
+	Code    string
+	Details string
+	Hints   string
 }
 
+// HandleHTTP is HandleHTTPErr with only logging options.
 func HandleHTTP(
 	ctx context.Context,
 	w http.ResponseWriter,
 	r *http.Request,
 	err error,
 	opts ...LogErrOption,
+) (handled bool) {
+	return HandleHTTPErr(ctx, w, r, err, &HandleHTTPErrOpts{LogOptions: opts})
+}
+
+// RecordSpanErrorFunc returns a HandleHTTPErrOpts.RecordSpanError hook
+// following the OpenTelemetry HTTP server conventions: record is called
+// with every handled error, e.g. to call span.RecordError, and setError only
+// for 5xx statuses, with the status text, e.g. to call
+// span.SetStatus(codes.Error, description). Either func may be nil.
+func RecordSpanErrorFunc(
+	record func(ctx context.Context, err error),
+	setError func(ctx context.Context, description string),
+) func(ctx context.Context, err error, status int) {
+	return func(ctx context.Context, err error, status int) {
+		if record != nil {
+			record(ctx, err)
+		}
+		if setError != nil && status >= 500 {
+			setError(ctx, http.StatusText(status))
+		}
+	}
+}
+
+// HandleHTTPErr logs err and writes the sanitized error response.
+// Returns false, without writing anything, if err is nil.
+// A nil opts uses the defaults.
+// Only the first call for a response has effect: later calls, e.g. from
+// nested middleware handling the same error, return true without logging
+// or writing. The handled state is kept by a BufferedResponseWriter, or
+// else by the slot WithError reserved on ctx or on the request context.
+func HandleHTTPErr(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+	opts *HandleHTTPErrOpts,
 ) (handled bool) {
 	if err == nil {
 		return false
 	}
-	status := GetHTTPCode(err)
-	config := LogErrOptions{
-		Logger:      slog.Default(),
-		LogLevel:    HTTPGetLogLevel(status),
-		LoggerAttrs: []any{},
+	if wasHandled(ctx, w, r) {
+		return true
 	}
+	tw := &trackingWriter{ResponseWriter: w}
+	defer markHandled(ctx, tw, r)
 
-	for _, opt := range opts {
+	if opts == nil {
+		opts = &HandleHTTPErrOpts{}
+	}
+	status, overridden := resolveStatus(err, opts)
+	config := DefaultLogErrOptions
+	config.LogLevel = HTTPGetLogLevel(status)
+	for _, opt := range opts.LogOptions {
 		opt(&config)
 	}
 	httpAttrs := []any{
@@ -79,53 +298,340 @@ func HandleHTTP(
 		"path", r.URL.Path,
 		"status", status,
 		"remote_addr", r.RemoteAddr,
+		"fingerprint", Fingerprint(err),
 	}
 
-	LogErr(
-		ctx,
-		err,
-		append(opts, LogErrUseLoggerAttrs(
-			httpAttrs...,
-		))...,
-	)
-
-	message := http.StatusText(status)
+	logConfig := config
+	logConfig.LoggerAttrs = append(append([]any{}, config.LoggerAttrs...), httpAttrs...)
+	logErr(ctx, err, logConfig)
+	if opts.RecordSpanError != nil {
+		opts.RecordSpanError(ctx, err, status)
+	}
+	if opts.StoreInContext && !storeError(ctx, err) {
+		storeError(r.Context(), err)
+	}
 
-	var e *Error
-	if !errors.As(err, &e) {
-		config.Logger.Log(ctx, config.LogLevel, err.Error(), config.LoggerAttrs...)
-		http.Error(w, message, status)
-		return
+	if bw, ok := w.(*BufferedResponseWriter); ok {
+		bw.Reset()
+	}
+	if runMarkerHandlers(tw, r, err, opts.MarkerHandlers) {
+		return true
 	}
 
-	LogErr(ctx, err, append(opts, LogErrUseLoggerAttrs(httpAttrs...))...)
+	body, safe := newErrorHTTPResponse(err, status, overridden, requestLanguage(r), opts)
+	if opts.ResponseTransformer != nil {
+		opts.ResponseTransformer(ctx, &body)
+	}
 
-	if e.SafeMessage != "" {
-		message = e.SafeMessage
-	} else if e.ExposeInternal {
-		message = e.Internal.Error()
+	if opts.MaxResponseBytes > 0 {
+		var (
+			size   int
+			notice string
+		)
+		body, size, notice = limitResponse(body, opts)
+		if notice != "" {
+			config.Logger.WarnContext(ctx, "error response truncated: "+notice,
+				append(httpAttrs, "size", size, "limit", opts.MaxResponseBytes)...,
+			)
+		}
 	}
 
-	resp, marshalErr := json.Marshal(ErrorHTTPResponse{
-		Error:   message,
-		Details: e.UserDetails,
-	})
-	if marshalErr != nil {
+	renderer := opts.Renderer
+	if renderer == nil {
+		renderer = JSONRenderer{FieldNaming: opts.FieldNaming, FieldNames: opts.FieldNames}
+	}
+	for key, values := range safe.Headers {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	metaKeys := slices.Sorted(maps.Keys(safe.Metadata))
+	if opts.IncludeMeta {
+		// Declared before the body is written, so the response is chunked
+		// and the trailers are sent.
+		for _, key := range metaKeys {
+			w.Header().Add("Trailer", key)
+		}
+	}
+	if renderErr := renderer.Render(tw, body, status); renderErr != nil {
 		config.Logger.ErrorContext(ctx,
-			fmt.Sprintf("failed to marshal error response: %v", marshalErr),
+			fmt.Sprintf("failed to render error response: %v", renderErr),
 			httpAttrs...,
 		)
-		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if opts.IncludeMeta {
+		for _, key := range metaKeys {
+			w.Header().Set(http.TrailerPrefix+key, safe.Metadata[key])
+		}
+	}
+	return true
+}
+
+// limitResponse shrinks body to opts.MaxResponseBytes: it drops the details
+// first and, if the body is still too large, keeps only the message.
+// It returns the original encoded size and a notice describing what was
+// dropped, which is empty if body was within the limit.
+func limitResponse(body ErrorHTTPResponse, opts *HandleHTTPErrOpts) (ErrorHTTPResponse, int, string) {
+	fits := func(b ErrorHTTPResponse) (int, bool) {
+		raw, err := marshalResponse(b, opts.FieldNaming, opts.FieldNames)
+		return len(raw), err != nil || len(raw) <= opts.MaxResponseBytes
+	}
+
+	size, ok := fits(body)
+	if ok {
+		return body, size, ""
+	}
+	body.Details = nil
+	if body.ErrorInfo != nil {
+		info := *body.ErrorInfo
+		info.Metadata = nil
+		body.ErrorInfo = &info
+	}
+	if _, ok := fits(body); ok {
+		return body, size, "details dropped"
+	}
+	return ErrorHTTPResponse{Error: body.Error}, size, "reduced to the error message"
+}
+
+// resolveStatus returns the response status of err, applying
+// opts.StatusOverride, and whether the override changed it.
+func resolveStatus(err error, opts *HandleHTTPErrOpts) (status int, overridden bool) {
+	status = GetHTTPCode(err)
+	if opts.StatusOverride != nil {
+		defaultStatus := status
+		status = opts.StatusOverride(err, defaultStatus)
+		overridden = status != defaultStatus
+	}
+	return status, overridden
+}
+
+// newErrorHTTPResponse builds the response body for err, with hints
+// localized into lang. It also returns the sanitized error, whose headers
+// and metadata accompany the body.
+func newErrorHTTPResponse(
+	err error,
+	status int,
+	overridden bool,
+	lang string,
+	opts *HandleHTTPErrOpts,
+) (ErrorHTTPResponse, *Error) {
+	safe := Sanitize(err)
+
+	var body ErrorHTTPResponse
+	if overridden {
+		// Only the new status is described: details, hints and error info
+		// of the original error could disclose it.
+		body.Error = statusMessage(status)
+		if opts.IncludeErrorCode {
+			body.Code = statusCode(status)
+		}
+	} else {
+		body = ErrorHTTPResponse{
+			Error:   safe.SafeMessage,
+			Details: safe.UserDetails,
+			Hints:   safe.Hints,
+		}
+		if opts.Localizer != nil {
+			body.Hints = GetLocalizedHints(err, opts.Localizer, lang)
+		}
+		if opts.IncludeErrorCode {
+			body.Code = Code(err)
+			body.ErrorInfo = &ErrorInfo{
+				Reason:   safe.Reason,
+				Domain:   safe.Domain,
+				Metadata: detailsMetadata(safe.UserDetails),
+			}
+		}
+	}
+	if DevMode {
+		body.Error = err.Error()
+		var e *Error
+		if errors.As(err, &e) {
+			body.Stack = string(e.Stack)
+		}
+		if opts.IncludeDebug {
+			body.Debug = newErrorDebug(err, body.Stack)
+		}
+	}
+	return body, safe
+}
+
+// WriteStreamError writes the sanitized response for err, as HandleHTTPErr
+// would render it with opts, as a single JSON line for NDJSON streams.
+// No headers or status are set and err is not logged. Hints are localized
+// with the empty language and opts.ResponseTransformer is called with
+// context.Background(). Writes nothing if err is nil.
+func WriteStreamError(w io.Writer, err error, opts *HandleHTTPErrOpts) error {
+	if err == nil {
+		return nil
+	}
+	if opts == nil {
+		opts = &HandleHTTPErrOpts{}
+	}
+
+	status, overridden := resolveStatus(err, opts)
+	body, _ := newErrorHTTPResponse(err, status, overridden, "", opts)
+	if opts.ResponseTransformer != nil {
+		opts.ResponseTransformer(context.Background(), &body)
+	}
+	line, marshalErr := marshalResponse(body, opts.FieldNaming, opts.FieldNames)
+	if marshalErr != nil {
+		return fmt.Errorf("marshal: %w", marshalErr)
+	}
+	if _, writeErr := w.Write(append(line, '\n')); writeErr != nil {
+		return fmt.Errorf("write: %w", writeErr)
+	}
+	return nil
+}
+
+// statusMessage returns the canonical message of the sentinel mapped to
+// status, or else its status text.
+func statusMessage(status int) string {
+	if sentinel, ok := mappedSentinel(status); ok {
+		if msg, ok := getCanonicalMessage(sentinel); ok {
+			return msg
+		}
+	}
+	return http.StatusText(status)
+}
+
+// statusCode returns the code of the sentinel mapped to status,
+// or else CodeFromStatus.
+func statusCode(status int) string {
+	if sentinel, ok := mappedSentinel(status); ok {
+		return Code(sentinel)
+	}
+	return CodeFromStatus(status)
+}
+
+// runMarkerHandlers calls the handlers matching err until one writes
+// to tw. Reports whether the response was written.
+func runMarkerHandlers(
+	tw *trackingWriter,
+	r *http.Request,
+	err error,
+	handlers map[error]func(http.ResponseWriter, *http.Request, error),
+) bool {
+	if len(handlers) == 0 {
+		return false
+	}
+	markers := make([]error, 0, len(handlers))
+	for marker := range handlers {
+		markers = append(markers, marker)
+	}
+	for _, marker := range sortedMarkers(markers) {
+		if !errors.Is(err, marker) {
+			continue
+		}
+		handlers[marker](tw, r, err)
+		if tw.wrote {
+			return true
+		}
+	}
+	return false
+}
+
+// handledSlot returns the slot reserved with WithError on ctx, or else on
+// the request context, or nil if there is none.
+func handledSlot(ctx context.Context, r *http.Request) *errorSlot {
+	if slot := errorSlotOf(ctx); slot != nil {
+		return slot
+	}
+	return errorSlotOf(r.Context())
+}
+
+// wasHandled reports whether HandleHTTPErr already handled the response.
+func wasHandled(ctx context.Context, w http.ResponseWriter, r *http.Request) bool {
+	if bw, ok := w.(*BufferedResponseWriter); ok {
+		return bw.handled
+	}
+	if slot := handledSlot(ctx, r); slot != nil {
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		return slot.handled
+	}
+	return w.Header().Get(handledHeader) != ""
+}
+
+// markHandled records that HandleHTTPErr handled the response of tw.
+// Without a BufferedResponseWriter or a context slot it falls back to
+// handledHeader, set only if a status was written so it is not sent.
+func markHandled(ctx context.Context, tw *trackingWriter, r *http.Request) {
+	if bw, ok := tw.ResponseWriter.(*BufferedResponseWriter); ok {
+		bw.handled = true
+		return
+	}
+	if slot := handledSlot(ctx, r); slot != nil {
+		slot.mu.Lock()
+		defer slot.mu.Unlock()
+		slot.handled = true
+		return
+	}
+	if tw.wrote {
+		tw.Header().Set(handledHeader, "1")
+	}
+}
+
+// trackingWriter records whether anything was written to the response.
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func newErrorDebug(err error, stack string) *ErrorDebug {
+	debug := &ErrorDebug{
+		Stack:      stack,
+		LogDetails: GetAllDetails(err),
+	}
+	for i, detail := range debug.LogDetails {
+		debug.LogDetails[i] = redact(detail)
+	}
+	Walk(err, func(err error) bool {
+		debug.Chain = append(debug.Chain, err.Error())
 		return true
+	})
+	return debug
+}
+
+// detailsMetadata flattens the top-level fields of details into string
+// metadata. String values are kept as is, other values are JSON encoded.
+// Returns nil if details do not encode to a JSON object.
+func detailsMetadata(details any) map[string]string {
+	if details == nil {
+		return nil
+	}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || len(fields) == 0 {
+		return nil
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if _, err = w.Write(resp); err != nil {
-		config.Logger.WarnContext(ctx,
-			fmt.Sprintf("failed to write error response: %v", err),
-			httpAttrs...,
-		)
+	metadata := make(map[string]string, len(fields))
+	for k, v := range fields {
+		var str string
+		if json.Unmarshal(v, &str) == nil {
+			metadata[k] = str
+		} else {
+			metadata[k] = string(v)
+		}
 	}
-	return true
+	return metadata
 }
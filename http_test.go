@@ -0,0 +1,1035 @@
+package errs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+var discard = errs.LogErrUseLogger(slog.New(slog.DiscardHandler))
+
+func TestHandleHTTP(t *testing.T) {
+	t.Run("copies error headers onto the response", func(t *testing.T) {
+		err := errs.Mark(
+			errs.New("missing bearer token"),
+			errs.ErrUnauthorized,
+			errs.WithHeader("WWW-Authenticate", `Bearer realm="api"`),
+		)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		if !errs.HandleHTTP(context.Background(), w, r, err, discard) {
+			t.Fatal("HandleHTTP() = false, want true")
+		}
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != `Bearer realm="api"` {
+			t.Errorf("WWW-Authenticate = %q, want %q", got, `Bearer realm="api"`)
+		}
+	})
+
+	t.Run("logs internal message but hides it from private responses", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errs.New("dial postgres://admin:s3cret@db: connection refused")
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if msg := entries[0]["msg"]; msg != err.Error() {
+			t.Errorf("logged msg = %q, want %q", msg, err.Error())
+		}
+		if entries[0]["level"] != slog.LevelError.String() {
+			t.Errorf("logged level = %v, want %v", entries[0]["level"], slog.LevelError)
+		}
+		if got := entries[0]["fingerprint"]; got != errs.Fingerprint(err) {
+			t.Errorf("logged fingerprint = %v, want %v", got, errs.Fingerprint(err))
+		}
+
+		if strings.Contains(w.Body.String(), "s3cret") {
+			t.Errorf("response body leaks internal message: %s", w.Body.String())
+		}
+		want := `{"error":"Internal Server Error"}`
+		if w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+
+	t.Run("exposes safe message while logging internal one", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errs.Mark(errs.New("token kid=42 expired"), errs.ErrUnauthorized, func(e *errs.Error) {
+			e.SafeMessage = "Session expired"
+		})
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if msg := entries[0]["msg"]; msg != "token kid=42 expired" {
+			t.Errorf("logged msg = %q, want internal message", msg)
+		}
+		if entries[0]["level"] != slog.LevelWarn.String() {
+			t.Errorf("logged level = %v, want %v", entries[0]["level"], slog.LevelWarn)
+		}
+		if want := `{"error":"Session expired"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+
+	t.Run("falls back to canonical message for known sentinels", func(t *testing.T) {
+		err := errs.Mark(errors.New("sql: no rows in result set"), errs.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+		if want := `{"error":"The requested resource was not found"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+}
+
+func TestRegisterCanonicalMessage(t *testing.T) {
+	errs.KeepRegistries(t)
+	errQuota := errors.New("quota exceeded")
+	errs.RegisterCanonicalMessage(errQuota, "Your plan quota is exhausted")
+
+	err := errs.Mark(errors.New("tenant 7 used 1001/1000 calls"), errQuota)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/calls", nil)
+	errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+	if want := `{"error":"Your plan quota is exhausted"}`; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+}
+
+func TestMarkFromStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, errs.ErrInvalidArgument},
+		{http.StatusUnauthorized, errs.ErrUnauthorized},
+		{http.StatusNotFound, errs.ErrNotFound},
+		{http.StatusConflict, errs.ErrExists},
+		{http.StatusTooManyRequests, errs.ErrRateLimited},
+		{http.StatusGatewayTimeout, errs.ErrDeadlineExceeded},
+		{http.StatusTeapot, errs.ErrInternal},
+		{http.StatusServiceUnavailable, errs.ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			base := errors.New("upstream call failed")
+			err := errs.MarkFromStatus(base, tt.status)
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(err, %v) = false, want true", tt.want)
+			}
+			if !errors.Is(err, base) {
+				t.Error("errors.Is(err, base) = false, want true")
+			}
+		})
+	}
+
+	t.Run("returns nil when err is nil", func(t *testing.T) {
+		if got := errs.MarkFromStatus(nil, http.StatusNotFound); got != nil {
+			t.Errorf("MarkFromStatus(nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestStatusText(t *testing.T) {
+	if got := errs.StatusText(errs.Mark(errors.New("no row"), errs.ErrNotFound)); got != "Not Found" {
+		t.Errorf("StatusText(404) = %q, want %q", got, "Not Found")
+	}
+	if got := errs.StatusText(errors.New("boom")); got != "Internal Server Error" {
+		t.Errorf("StatusText(foreign) = %q, want %q", got, "Internal Server Error")
+	}
+}
+
+func TestGetHTTPCode(t *testing.T) {
+	t.Run("first matching mapping wins for conflicting markers", func(t *testing.T) {
+		err := errs.Mark(errs.Mark(errors.New("bad id"), errs.ErrNotFound), errs.ErrInvalidArgument)
+
+		if got := errs.GetHTTPCode(err); got != http.StatusBadRequest {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("respects customized precedence", func(t *testing.T) {
+		orig := errs.HTTPCodeMappings
+		t.Cleanup(func() { errs.HTTPCodeMappings = orig })
+		errs.HTTPCodeMappings = append(
+			[]errs.HTTPCodeMapping{{Sentinel: errs.ErrNotFound, Status: http.StatusNotFound}},
+			orig...,
+		)
+
+		err := errs.Mark(errs.Mark(errors.New("bad id"), errs.ErrInvalidArgument), errs.ErrNotFound)
+
+		if got := errs.GetHTTPCode(err); got != http.StatusNotFound {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusNotFound)
+		}
+	})
+
+	t.Run("sees markers through foreign wrappers", func(t *testing.T) {
+		marked := errs.Mark(errors.New("no row"), errs.ErrNotFound)
+
+		for name, err := range map[string]error{
+			"fmt.Errorf":        fmt.Errorf("ctx: %w", marked),
+			"nested fmt.Errorf": fmt.Errorf("handler: %w", fmt.Errorf("ctx: %w", marked)),
+			"multiple %w":       fmt.Errorf("ctx: %w, %w", errors.New("other"), marked),
+			"errors.Join":       errors.Join(errors.New("other"), marked),
+		} {
+			if got := errs.GetHTTPCode(err); got != http.StatusNotFound {
+				t.Errorf("%s: GetHTTPCode() = %d, want %d", name, got, http.StatusNotFound)
+			}
+		}
+	})
+
+	t.Run("defaults to 500", func(t *testing.T) {
+		if got := errs.GetHTTPCode(errors.New("boom")); got != http.StatusInternalServerError {
+			t.Errorf("GetHTTPCode() = %d, want %d", got, http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestHandleHTTPErr(t *testing.T) {
+	newErr := func() error {
+		return errs.Mark(errors.New("user 123 is disabled"), errs.ErrPermissionDenied,
+			errs.WithReason("USER_DISABLED"),
+			func(e *errs.Error) {
+				e.Domain = "users"
+				e.SafeMessage = "Your account is disabled"
+				e.UserDetails = map[string]any{"user_id": "123", "attempts": 3}
+			},
+		)
+	}
+
+	t.Run("includes error_info when IncludeErrorCode is set", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/me", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, newErr(), &errs.HandleHTTPErrOpts{
+			LogOptions:       []errs.LogErrOption{discard},
+			IncludeErrorCode: true,
+		})
+
+		var body errs.ErrorHTTPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.ErrorInfo == nil {
+			t.Fatalf("error_info missing from %s", w.Body.String())
+		}
+		if body.ErrorInfo.Reason != "USER_DISABLED" {
+			t.Errorf("reason = %q, want %q", body.ErrorInfo.Reason, "USER_DISABLED")
+		}
+		if body.ErrorInfo.Domain != "users" {
+			t.Errorf("domain = %q, want %q", body.ErrorInfo.Domain, "users")
+		}
+		wantMeta := map[string]string{"user_id": "123", "attempts": "3"}
+		if len(body.ErrorInfo.Metadata) != len(wantMeta) {
+			t.Fatalf("metadata = %v, want %v", body.ErrorInfo.Metadata, wantMeta)
+		}
+		for k, v := range wantMeta {
+			if body.ErrorInfo.Metadata[k] != v {
+				t.Errorf("metadata[%q] = %q, want %q", k, body.ErrorInfo.Metadata[k], v)
+			}
+		}
+	})
+
+	t.Run("omits error_info by default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/me", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, newErr(), &errs.HandleHTTPErrOpts{
+			LogOptions: []errs.LogErrOption{discard},
+		})
+
+		if strings.Contains(w.Body.String(), "error_info") {
+			t.Errorf("body = %s, want no error_info", w.Body.String())
+		}
+	})
+
+	t.Run("accepts nil opts", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/me", nil)
+		orig := errs.DefaultLogErrOptions
+		t.Cleanup(func() { errs.DefaultLogErrOptions = orig })
+		errs.DefaultLogErrOptions.Logger = slog.New(slog.DiscardHandler)
+
+		if !errs.HandleHTTPErr(context.Background(), w, r, newErr(), nil) {
+			t.Fatal("HandleHTTPErr() = false, want true")
+		}
+		if w.Code != http.StatusForbidden {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+		}
+	})
+}
+
+func TestDevMode(t *testing.T) {
+	handle := func(t *testing.T, devMode bool) *httptest.ResponseRecorder {
+		t.Helper()
+		orig := errs.DevMode
+		t.Cleanup(func() { errs.DevMode = orig })
+		errs.DevMode = devMode
+
+		err := errs.Wrap(errs.New("pq: password authentication failed", errs.WithStack()), "connect db")
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+		return w
+	}
+
+	t.Run("exposes internal message and stack in dev", func(t *testing.T) {
+		w := handle(t, true)
+
+		var body errs.ErrorHTTPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if want := "connect db: pq: password authentication failed"; body.Error != want {
+			t.Errorf("error = %q, want %q", body.Error, want)
+		}
+		if !strings.Contains(body.Stack, "TestDevMode") {
+			t.Errorf("stack = %q, want captured stack", body.Stack)
+		}
+	})
+
+	t.Run("hides internal message and stack in production", func(t *testing.T) {
+		w := handle(t, false)
+
+		if strings.Contains(w.Body.String(), "password") {
+			t.Errorf("body leaks internal message: %s", w.Body.String())
+		}
+		if want := `{"error":"Internal Server Error"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+}
+
+func TestIncludeDebug(t *testing.T) {
+	handle := func(t *testing.T, devMode bool, naming errs.FieldNaming) map[string]any {
+		t.Helper()
+		orig := errs.DevMode
+		t.Cleanup(func() { errs.DevMode = orig })
+		errs.DevMode = devMode
+
+		err := errs.Wrap(
+			errs.New("pq: password authentication failed", errs.WithStack(), func(e *errs.Error) {
+				e.LogDetails = append(e.LogDetails, "host", "db-1")
+			}),
+			"connect db",
+		)
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions:   []errs.LogErrOption{discard},
+			IncludeDebug: true,
+			FieldNaming:  naming,
+		})
+
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		return body
+	}
+
+	t.Run("adds debug object in dev", func(t *testing.T) {
+		body := handle(t, true, errs.FieldNamingSnake)
+
+		debug, ok := body["debug"].(map[string]any)
+		if !ok {
+			t.Fatalf("debug = %v, want object", body["debug"])
+		}
+		chain, _ := debug["chain"].([]any)
+		if len(chain) < 2 || chain[0] != "connect db: pq: password authentication failed" {
+			t.Errorf("chain = %v, want unwrap chain starting with the handled error", chain)
+		}
+		if stack, _ := debug["stack"].(string); !strings.Contains(stack, "TestIncludeDebug") {
+			t.Errorf("stack = %q, want captured stack", stack)
+		}
+		if details, _ := debug["log_details"].([]any); len(details) != 2 || details[0] != "host" || details[1] != "db-1" {
+			t.Errorf("log_details = %v, want [host db-1]", details)
+		}
+	})
+
+	t.Run("renames debug keys with camel case", func(t *testing.T) {
+		body := handle(t, true, errs.FieldNamingCamel)
+
+		debug, ok := body["debug"].(map[string]any)
+		if !ok {
+			t.Fatalf("debug = %v, want object", body["debug"])
+		}
+		if _, ok := debug["log_details"]; ok {
+			t.Errorf("debug = %v, want no snake_case keys", debug)
+		}
+		if details, _ := debug["logDetails"].([]any); len(details) != 2 {
+			t.Errorf("logDetails = %v, want [host db-1]", details)
+		}
+	})
+
+	t.Run("omits debug object in production", func(t *testing.T) {
+		body := handle(t, false, errs.FieldNamingSnake)
+
+		if _, ok := body["debug"]; ok {
+			t.Errorf("body has debug object in production: %v", body)
+		}
+	})
+}
+
+func TestFieldNaming(t *testing.T) {
+	err := errs.Mark(errors.New("user disabled"), errs.ErrPermissionDenied,
+		errs.WithReason("USER_DISABLED"),
+		func(e *errs.Error) {
+			e.UserDetails = map[string]any{"user_id": "7"}
+		},
+	)
+
+	tests := []struct {
+		name   string
+		naming errs.FieldNaming
+		want   string
+	}{
+		{
+			name:   "snake case",
+			naming: errs.FieldNamingSnake,
+			want: `{"error":"You do not have permission to perform this action","code":"PERMISSION_DENIED",` +
+				`"details":{"user_id":"7"},"error_info":{"reason":"USER_DISABLED","metadata":{"user_id":"7"}}}`,
+		},
+		{
+			name:   "camel case",
+			naming: errs.FieldNamingCamel,
+			want: `{"code":"PERMISSION_DENIED","details":{"user_id":"7"},"error":"You do not have permission to perform this action",` +
+				`"errorInfo":{"reason":"USER_DISABLED","metadata":{"user_id":"7"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/me", nil)
+			errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+				LogOptions:       []errs.LogErrOption{discard},
+				IncludeErrorCode: true,
+				FieldNaming:      tt.naming,
+			})
+
+			if w.Body.String() != tt.want {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseFieldNames(t *testing.T) {
+	err := errs.Mark(errors.New("user disabled"), errs.ErrPermissionDenied,
+		errs.WithHint("contact support"),
+		func(e *errs.Error) {
+			e.UserDetails = map[string]any{"user_id": "7"}
+		},
+	)
+
+	tests := []struct {
+		name   string
+		naming errs.FieldNaming
+		want   string
+	}{
+		{
+			name:   "snake case",
+			naming: errs.FieldNamingSnake,
+			want: `{"error_code":"PERMISSION_DENIED","error_info":{"metadata":{"user_id":"7"}},"hints":["contact support"],` +
+				`"message":"You do not have permission to perform this action","payload":{"user_id":"7"}}`,
+		},
+		{
+			name:   "camel case",
+			naming: errs.FieldNamingCamel,
+			want: `{"errorInfo":{"metadata":{"user_id":"7"}},"error_code":"PERMISSION_DENIED","hints":["contact support"],` +
+				`"message":"You do not have permission to perform this action","payload":{"user_id":"7"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/me", nil)
+			errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+				LogOptions:       []errs.LogErrOption{discard},
+				IncludeErrorCode: true,
+				FieldNaming:      tt.naming,
+				FieldNames: errs.ResponseFieldNames{
+					Error:   "message",
+					Code:    "error_code",
+					Details: "payload",
+				},
+			})
+
+			if w.Body.String() != tt.want {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleHTTPHints(t *testing.T) {
+	err := errs.Wrap(
+		errs.Mark(errors.New("upstream busy"), errs.ErrRateLimited, errs.WithHint("retry later")),
+		"sync", errs.WithHint("retry later"),
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/sync", nil)
+	errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+	want := `{"error":"Too many requests, please try again later","hints":["retry later"]}`
+	if w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+}
+
+func TestWithUserDetailsFunc(t *testing.T) {
+	calls := 0
+	err := errs.Wrap(
+		errs.Mark(errors.New("quota check failed"), errs.ErrRateLimited,
+			errs.WithUserDetailsFunc(func() any {
+				calls++
+				return map[string]int{"retry_after": 30}
+			}),
+		),
+		"upload",
+	)
+
+	logger, _ := newLogBuffer()
+	errs.LogErr(context.Background(), err, errs.LogErrUseLogger(logger))
+	if calls != 0 {
+		t.Fatalf("func called %d times before rendering, want 0", calls)
+	}
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		want := `{"error":"Too many requests, please try again later","details":{"retry_after":30}}`
+		if w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("func called %d times, want 1", calls)
+	}
+}
+
+func TestResponseTransformer(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "req-42")
+	opts := &errs.HandleHTTPErrOpts{
+		LogOptions: []errs.LogErrOption{discard},
+		ResponseTransformer: func(ctx context.Context, resp *errs.ErrorHTTPResponse) {
+			resp.Extra = map[string]any{
+				"request_id": ctx.Value(ctxKey{}),
+				"error":      "overwritten",
+			}
+		},
+	}
+
+	t.Run("adds fields to the body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		errs.HandleHTTPErr(ctx, w, r, errs.Mark(errors.New("no row"), errs.ErrNotFound), opts)
+
+		want := `{"error":"The requested resource was not found","request_id":"req-42"}`
+		if w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+
+	t.Run("keeps extra keys with camel case naming", func(t *testing.T) {
+		camel := *opts
+		camel.FieldNaming = errs.FieldNamingCamel
+		camel.IncludeErrorCode = true
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		errs.HandleHTTPErr(ctx, w, r, errs.Mark(errors.New("no row"), errs.ErrNotFound), &camel)
+
+		want := `{"code":"NOT_FOUND","error":"The requested resource was not found","errorInfo":{},"request_id":"req-42"}`
+		if w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+}
+
+func TestRecordSpanError(t *testing.T) {
+	t.Run("calls the hook with the resolved status", func(t *testing.T) {
+		var (
+			calls     int
+			gotErr    error
+			gotStatus int
+		)
+		err := errs.Mark(errors.New("no row"), errs.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions: []errs.LogErrOption{discard},
+			RecordSpanError: func(_ context.Context, err error, status int) {
+				calls++
+				gotErr, gotStatus = err, status
+			},
+		})
+
+		if calls != 1 {
+			t.Fatalf("hook called %d times, want 1", calls)
+		}
+		if gotErr != err {
+			t.Errorf("hook err = %v, want %v", gotErr, err)
+		}
+		if gotStatus != http.StatusNotFound {
+			t.Errorf("hook status = %d, want %d", gotStatus, http.StatusNotFound)
+		}
+	})
+
+	t.Run("RecordSpanErrorFunc records errors and sets 5xx status", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			err      error
+			wantDesc []string
+		}{
+			{"client error", errs.Mark(errors.New("no row"), errs.ErrNotFound), nil},
+			{"server error", errs.Mark(errors.New("timeout"), errs.ErrDeadlineExceeded), []string{"Gateway Timeout"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var (
+					recorded []error
+					descs    []string
+				)
+				hook := errs.RecordSpanErrorFunc(
+					func(_ context.Context, err error) { recorded = append(recorded, err) },
+					func(_ context.Context, desc string) { descs = append(descs, desc) },
+				)
+
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+				errs.HandleHTTPErr(context.Background(), w, r, tt.err, &errs.HandleHTTPErrOpts{
+					LogOptions:      []errs.LogErrOption{discard},
+					RecordSpanError: hook,
+				})
+
+				if len(recorded) != 1 || recorded[0] != tt.err {
+					t.Errorf("recorded = %v, want [%v]", recorded, tt.err)
+				}
+				if !reflect.DeepEqual(descs, tt.wantDesc) {
+					t.Errorf("status descriptions = %q, want %q", descs, tt.wantDesc)
+				}
+			})
+		}
+	})
+
+	t.Run("RecordSpanErrorFunc accepts nil funcs", func(t *testing.T) {
+		errs.RecordSpanErrorFunc(nil, nil)(context.Background(), errors.New("boom"), 500)
+	})
+
+	t.Run("is optional", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, errors.New("boom"), &errs.HandleHTTPErrOpts{
+			LogOptions: []errs.LogErrOption{discard},
+		})
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	huge := make([]string, 1000)
+	for i := range huge {
+		huge[i] = "invalid row"
+	}
+	err := errs.Mark(errors.New("import failed"), errs.ErrInvalidArgument, func(e *errs.Error) {
+		e.SafeMessage = "Import failed"
+		e.UserDetails = map[string]any{"rows": huge}
+	})
+
+	t.Run("drops details from oversized responses", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/import", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions:       []errs.LogErrOption{errs.LogErrUseLogger(logger)},
+			MaxResponseBytes: 1024,
+		})
+
+		if want := `{"error":"Import failed"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+
+		entries := logEntries(t, buf)
+		if len(entries) != 2 {
+			t.Fatalf("got %d log entries, want 2", len(entries))
+		}
+		if msg, _ := entries[1]["msg"].(string); !strings.Contains(msg, "truncated") {
+			t.Errorf("second log msg = %q, want truncation notice", msg)
+		}
+		if entries[1]["limit"] != float64(1024) {
+			t.Errorf("limit = %v, want 1024", entries[1]["limit"])
+		}
+	})
+
+	t.Run("falls back to the message when still oversized", func(t *testing.T) {
+		hinted := errs.Mark(err, errs.ErrInvalidArgument,
+			errs.WithHint(strings.Repeat("check the file encoding ", 20)),
+			errs.WithHint(strings.Repeat("split the file into parts ", 20)),
+		)
+
+		logger, buf := newLogBuffer()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/import", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, hinted, &errs.HandleHTTPErrOpts{
+			LogOptions:       []errs.LogErrOption{errs.LogErrUseLogger(logger)},
+			IncludeErrorCode: true,
+			MaxResponseBytes: 256,
+		})
+
+		if want := `{"error":"Import failed"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+		entries := logEntries(t, buf)
+		if len(entries) != 2 {
+			t.Fatalf("got %d log entries, want 2", len(entries))
+		}
+		if msg, _ := entries[1]["msg"].(string); !strings.Contains(msg, "error message") {
+			t.Errorf("second log msg = %q, want minimal body notice", msg)
+		}
+	})
+
+	t.Run("keeps details within the limit", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/import", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions:       []errs.LogErrOption{discard},
+			MaxResponseBytes: 1 << 20,
+		})
+
+		if !strings.Contains(w.Body.String(), `"details"`) {
+			t.Errorf("details missing from %d byte body", w.Body.Len())
+		}
+	})
+}
+
+func TestMarkerHandlers(t *testing.T) {
+	handlers := map[error]func(http.ResponseWriter, *http.Request, error){
+		errs.ErrUnauthorized: func(w http.ResponseWriter, r *http.Request, _ error) {
+			http.Redirect(w, r, "/login", http.StatusFound)
+		},
+		errs.ErrNotFound: func(w http.ResponseWriter, _ *http.Request, _ error) {
+			w.Header().Set("X-Seen", "1") // does not write, falls through
+		},
+	}
+	handle := func(err error) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/account", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions:     []errs.LogErrOption{discard},
+			MarkerHandlers: handlers,
+		})
+		return w
+	}
+
+	t.Run("handler writing the response short-circuits", func(t *testing.T) {
+		w := handle(errs.Mark(errors.New("session expired"), errs.ErrUnauthorized))
+
+		if w.Code != http.StatusFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusFound)
+		}
+		if got := w.Header().Get("Location"); got != "/login" {
+			t.Errorf("Location = %q, want %q", got, "/login")
+		}
+		if strings.Contains(w.Body.String(), `"error"`) {
+			t.Errorf("body = %s, want no default error response", w.Body.String())
+		}
+	})
+
+	t.Run("falls through when handler does not write", func(t *testing.T) {
+		w := handle(errs.Mark(errors.New("no row"), errs.ErrNotFound))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+		if w.Header().Get("X-Seen") != "1" {
+			t.Error("handler for ErrNotFound was not called")
+		}
+	})
+
+	t.Run("ignores unmatched markers", func(t *testing.T) {
+		w := handle(errors.New("boom"))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestStatusOverride(t *testing.T) {
+	hideForbidden := func(_ error, status int) int {
+		if status == http.StatusForbidden {
+			return http.StatusNotFound
+		}
+		return status
+	}
+	handle := func(err error) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/projects/7", nil)
+		errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+			LogOptions:       []errs.LogErrOption{discard},
+			IncludeErrorCode: true,
+			StatusOverride:   hideForbidden,
+		})
+		return w
+	}
+
+	t.Run("rewrites 403 to 404", func(t *testing.T) {
+		w := handle(errs.Mark(errors.New("user 3 not a member of project 7"), errs.ErrPermissionDenied,
+			errs.WithReason("NOT_PROJECT_MEMBER"),
+			errs.WithHint("ask a project admin for access"),
+			func(e *errs.Error) {
+				e.SafeMessage = "You are not a member of this project"
+				e.Domain = "projects"
+				e.UserDetails = map[string]string{"required_role": "project_admin"}
+			},
+		))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+		var body errs.ErrorHTTPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if want := "The requested resource was not found"; body.Error != want {
+			t.Errorf("error = %q, want %q", body.Error, want)
+		}
+		if body.Code != "NOT_FOUND" {
+			t.Errorf("code = %q, want %q", body.Code, "NOT_FOUND")
+		}
+		if body.Details != nil || body.Hints != nil || body.ErrorInfo != nil {
+			t.Errorf("body = %s, want no details, hints or error_info", w.Body.String())
+		}
+		for _, leak := range []string{"project_admin", "NOT_PROJECT_MEMBER", "projects", "ask a project admin"} {
+			if strings.Contains(w.Body.String(), leak) {
+				t.Errorf("body leaks %q: %s", leak, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("keeps other statuses", func(t *testing.T) {
+		w := handle(errs.Mark(errors.New("email taken"), errs.ErrExists))
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+		}
+		if !strings.Contains(w.Body.String(), "The resource already exists") {
+			t.Errorf("body = %s, want canonical conflict message", w.Body.String())
+		}
+	})
+}
+
+func TestIncludeMeta(t *testing.T) {
+	err := errs.Mark(errors.New("replica lag"), errs.ErrRemoteServiceErr,
+		errs.WithMeta("X-Retry-Region", "eu-west-1"),
+		errs.WithMeta("X-Shard", "7"),
+	)
+	// A real server, unlike ResponseRecorder, drops trailers
+	// that were not declared before the body was written.
+	handle := func(includeMeta bool) (*http.Response, []byte) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			errs.HandleHTTPErr(r.Context(), w, r, err, &errs.HandleHTTPErrOpts{
+				LogOptions:  []errs.LogErrOption{discard},
+				IncludeMeta: includeMeta,
+			})
+		}))
+		defer srv.Close()
+
+		resp, getErr := http.Get(srv.URL + "/orders")
+		if getErr != nil {
+			t.Fatalf("GET: %v", getErr)
+		}
+		defer resp.Body.Close()
+		// Trailers are only available once the body is read.
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			t.Fatalf("read body: %v", readErr)
+		}
+		return resp, body
+	}
+
+	t.Run("sends metadata as trailers", func(t *testing.T) {
+		resp, body := handle(true)
+
+		if got := resp.Trailer.Get("X-Retry-Region"); got != "eu-west-1" {
+			t.Errorf("X-Retry-Region trailer = %q, want %q", got, "eu-west-1")
+		}
+		if got := resp.Trailer.Get("X-Shard"); got != "7" {
+			t.Errorf("X-Shard trailer = %q, want %q", got, "7")
+		}
+		if strings.Contains(string(body), "eu-west-1") {
+			t.Errorf("body leaks metadata: %s", body)
+		}
+	})
+
+	t.Run("omits trailers by default", func(t *testing.T) {
+		resp, _ := handle(false)
+
+		if len(resp.Trailer) != 0 {
+			t.Errorf("trailers = %v, want none", resp.Trailer)
+		}
+	})
+}
+
+func TestHandleHTTPErrWritesOnce(t *testing.T) {
+	t.Run("second call is a no-op", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		opts := &errs.HandleHTTPErrOpts{LogOptions: []errs.LogErrOption{errs.LogErrUseLogger(logger)}}
+
+		first := errs.HandleHTTPErr(context.Background(), w, r, errs.Mark(errors.New("no row"), errs.ErrNotFound), opts)
+		second := errs.HandleHTTPErr(context.Background(), w, r, errors.New("outer middleware"), opts)
+
+		if !first || !second {
+			t.Errorf("handled = %v, %v, want true, true", first, second)
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+		if want := `{"error":"The requested resource was not found"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+		if got := len(logEntries(t, buf)); got != 1 {
+			t.Errorf("got %d log entries, want 1", got)
+		}
+		if got := w.Result().Header.Get("X-Errs-Handled"); got != "" {
+			t.Errorf("marker header sent to client: %q", got)
+		}
+	})
+
+	t.Run("buffered writer does not send the marker", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		rec := httptest.NewRecorder()
+		w := errs.NewBufferedResponseWriter(rec)
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+
+		errs.HandleHTTP(context.Background(), w, r, errors.New("boom"), errs.LogErrUseLogger(logger))
+		errs.HandleHTTP(context.Background(), w, r, errors.New("boom"), errs.LogErrUseLogger(logger))
+		if err := w.Commit(); err != nil {
+			t.Fatalf("Commit() = %v", err)
+		}
+
+		if got := len(logEntries(t, buf)); got != 1 {
+			t.Errorf("got %d log entries, want 1", got)
+		}
+
+		if want := `{"error":"Internal Server Error"}`; rec.Body.String() != want {
+			t.Errorf("body = %s, want %s", rec.Body.String(), want)
+		}
+		if got := rec.Result().Header.Get("X-Errs-Handled"); got != "" {
+			t.Errorf("marker header sent to client: %q", got)
+		}
+	})
+
+	t.Run("context slot records the handled state", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		w := httptest.NewRecorder()
+		ctx := errs.WithError(context.Background(), nil)
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil).WithContext(ctx)
+
+		errs.HandleHTTP(ctx, w, r, errors.New("boom"), errs.LogErrUseLogger(logger))
+		errs.HandleHTTP(r.Context(), w, r, errors.New("boom"), errs.LogErrUseLogger(logger))
+
+		if got := len(logEntries(t, buf)); got != 1 {
+			t.Errorf("got %d log entries, want 1", got)
+		}
+		if got := w.Header().Get("X-Errs-Handled"); got != "" {
+			t.Errorf("marker header set: %q", got)
+		}
+	})
+
+	t.Run("no marker when the renderer wrote nothing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+		opts := &errs.HandleHTTPErrOpts{
+			Renderer:   failingRenderer{},
+			LogOptions: []errs.LogErrOption{discard},
+		}
+
+		errs.HandleHTTPErr(context.Background(), w, r, errors.New("boom"), opts)
+
+		if got := w.Header().Get("X-Errs-Handled"); got != "" {
+			t.Errorf("marker header set without a written status: %q", got)
+		}
+	})
+}
+
+type failingRenderer struct{}
+
+func (failingRenderer) Render(http.ResponseWriter, errs.ErrorHTTPResponse, int) error {
+	return errors.New("encoder unavailable")
+}
+
+func TestWriteStreamError(t *testing.T) {
+	t.Run("writes a sanitized JSON line", func(t *testing.T) {
+		err := errs.Mark(errors.New("user 7 token=abc123 revoked"), errs.ErrPermissionDenied, func(e *errs.Error) {
+			e.LogDetails = []any{"token", "abc123"}
+		})
+
+		var buf bytes.Buffer
+		if writeErr := errs.WriteStreamError(&buf, err, &errs.HandleHTTPErrOpts{IncludeErrorCode: true}); writeErr != nil {
+			t.Fatalf("WriteStreamError() = %v", writeErr)
+		}
+
+		want := `{"error":"You do not have permission to perform this action","code":"PERMISSION_DENIED","error_info":{}}` + "\n"
+		if buf.String() != want {
+			t.Errorf("line = %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("writes nothing for nil", func(t *testing.T) {
+		var buf bytes.Buffer
+		if writeErr := errs.WriteStreamError(&buf, nil, nil); writeErr != nil || buf.Len() != 0 {
+			t.Errorf("WriteStreamError(nil) = %v, wrote %q", writeErr, buf.String())
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package errs
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Localizer translates user-facing message keys into a language.
+// Localize reports false if it has no translation for key in lang,
+// in which case the key is used literally.
+type Localizer interface {
+	Localize(lang, key string) (string, bool)
+}
+
+// GetLocalizedHints is GetAllHints with every hint translated by loc into
+// lang. Hints without a translation are kept as is, so literal hints keep
+// working. A nil loc returns the hints untranslated.
+func GetLocalizedHints(err error, loc Localizer, lang string) []string {
+	hints := GetAllHints(err)
+	if loc == nil {
+		return hints
+	}
+	for i, hint := range hints {
+		if localized, ok := loc.Localize(lang, hint); ok {
+			hints[i] = localized
+		}
+	}
+	return hints
+}
+
+// requestLanguage returns the first language tag of the Accept-Language
+// header of r, e.g. "de-CH" for "de-CH, de;q=0.9, en;q=0.8".
+// Returns an empty string if the header is missing.
+func requestLanguage(r *http.Request) string {
+	header := r.Header.Get("Accept-Language")
+	tag, _, _ := strings.Cut(header, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	return strings.TrimSpace(tag)
+}
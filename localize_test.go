@@ -0,0 +1,67 @@
+package errs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+type mapLocalizer map[string]map[string]string
+
+func (l mapLocalizer) Localize(lang, key string) (string, bool) {
+	msg, ok := l[lang][key]
+	return msg, ok
+}
+
+func TestLocalizedHints(t *testing.T) {
+	loc := mapLocalizer{
+		"en": {"hint.retry": "Try again in a minute"},
+		"de": {"hint.retry": "Versuchen Sie es in einer Minute erneut"},
+	}
+	err := errs.Mark(errors.New("upstream busy"), errs.ErrRateLimited,
+		errs.WithHint("hint.retry"),
+		errs.WithHint("Contact support if it persists"),
+	)
+
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"en", []string{"Try again in a minute", "Contact support if it persists"}},
+		{"de, en;q=0.5", []string{"Versuchen Sie es in einer Minute erneut", "Contact support if it persists"}},
+		{"", []string{"hint.retry", "Contact support if it persists"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/sync", nil)
+			r.Header.Set("Accept-Language", tt.lang)
+			errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+				LogOptions: []errs.LogErrOption{discard},
+				Localizer:  loc,
+			})
+
+			var body errs.ErrorHTTPResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if !slices.Equal(body.Hints, tt.want) {
+				t.Errorf("hints = %q, want %q", body.Hints, tt.want)
+			}
+		})
+	}
+
+	t.Run("keeps literal hints without localizer", func(t *testing.T) {
+		got := errs.GetLocalizedHints(err, nil, "de")
+		if want := []string{"hint.retry", "Contact support if it persists"}; !slices.Equal(got, want) {
+			t.Errorf("hints = %q, want %q", got, want)
+		}
+	})
+}
@@ -3,13 +3,18 @@ package errs
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"runtime"
+	"sync"
+	"time"
 )
 
 var DefaultLogErrOptions = LogErrOptions{
-	Logger:      slog.Default(),
-	LogLevel:    slog.LevelError,
-	LoggerAttrs: []any{},
+	Logger:       slog.Default(),
+	LogLevel:     slog.LevelError,
+	LoggerAttrs:  []any{},
+	DetailsGroup: "error_details",
 }
 
 func LogErr(ctx context.Context, err error, opts ...LogErrOption) {
@@ -20,19 +25,138 @@ func LogErr(ctx context.Context, err error, opts ...LogErrOption) {
 	for _, opt := range opts {
 		opt(&config)
 	}
+	logErr(ctx, err, config)
+}
+
+// LogWarn logs err at Warn level, regardless of its severity, for errors
+// that are recovered from rather than returned. The level overrides
+// LogErrUseLogLevel, LogErrUseDomainLevel and the error's own LogLevel.
+func LogWarn(ctx context.Context, err error, opts ...LogErrOption) {
+	logAt(ctx, err, slog.LevelWarn, opts)
+}
+
+// LogInfo is LogWarn at Info level.
+func LogInfo(ctx context.Context, err error, opts ...LogErrOption) {
+	logAt(ctx, err, slog.LevelInfo, opts)
+}
+
+// LogDebug is LogWarn at Debug level.
+func LogDebug(ctx context.Context, err error, opts ...LogErrOption) {
+	logAt(ctx, err, slog.LevelDebug, opts)
+}
 
+func logAt(ctx context.Context, err error, level slog.Level, opts []LogErrOption) {
+	if err == nil {
+		return
+	}
+	config := DefaultLogErrOptions
+	for _, opt := range opts {
+		opt(&config)
+	}
+	config.LogLevel = level
+	config.fixedLevel = true
+	logErr(ctx, err, config)
+}
+
+// WrapLog wraps err with msg, logs the result with LogErr and returns it.
+// Returns nil, without logging, if err is nil.
+func WrapLog(ctx context.Context, err error, msg string, opts ...LogErrOption) error {
+	err = Wrap(err, msg)
+	LogErr(ctx, err, opts...)
+	return err
+}
+
+// logErr emits err using a fully resolved config.
+// The complete internal message is always logged, regardless of
+// ExposeInternal or SafeMessage, which only affect responses.
+func logErr(ctx context.Context, err error, config LogErrOptions) {
+	if IsAny(err, config.SkipSentinels...) {
+		return
+	}
+	if config.ThrottleKey != "" && config.ThrottleEvery > 0 {
+		allowed, suppressed := allowLog(config.ThrottleKey, config.ThrottleEvery)
+		if !allowed {
+			return
+		}
+		if suppressed > 0 {
+			config.LoggerAttrs = append(
+				append([]any{}, config.LoggerAttrs...),
+				"suppressed", suppressed,
+			)
+		}
+	}
+
+	level := config.LogLevel
 	var e *Error
-	if errors.As(err, &e) {
-		attrs := make([]any, 0)
-		attrs = append(attrs, e.LogDetails...)
+	if !config.fixedLevel && errors.As(err, &e) {
+		if l, ok := config.DomainLevels[e.Domain]; ok {
+			level = l
+		}
+		if e.LogLevel != nil {
+			level = *e.LogLevel
+		}
+	}
+	config.Logger.Log(ctx, level, err.Error(), BuildLogAttrs(err, &config)...)
+}
+
+// BuildLogAttrs returns the attributes LogErr emits for err with opts,
+// without logging anything, e.g. to assert them in tests. The record message
+// is err.Error(). A nil opts means DefaultLogErrOptions.
+// Struct fields tagged `errs:"secret"` in LogDetails are redacted.
+// Returns nil if err is nil.
+func BuildLogAttrs(err error, opts *LogErrOptions) []any {
+	if err == nil {
+		return nil
+	}
+	config := DefaultLogErrOptions
+	if opts != nil {
+		config = *opts
+	}
+
+	attrs := make([]any, 0)
+	var e *Error
+	isError := errors.As(err, &e)
+	if isError {
+		details := make([]any, 0, len(e.LogDetails))
+		for _, detail := range e.LogDetails {
+			details = append(details, redact(detail))
+		}
+		details, truncated := truncateDetails(details, config.MaxDetails)
+		if config.DetailsGroup != "" && len(details) > 0 {
+			attrs = append(attrs, slog.Group(config.DetailsGroup, details...))
+		} else {
+			attrs = append(attrs, details...)
+		}
+		if truncated {
+			attrs = append(attrs, "details_truncated", true)
+		}
 		if e.Domain != "" {
 			attrs = append(attrs, "domain", e.Domain)
 		}
-		attrs = append(attrs, config.LoggerAttrs...)
-		config.Logger.Log(ctx, config.LogLevel, err.Error(), attrs...)
-	} else {
-		config.Logger.Log(ctx, config.LogLevel, err.Error(), config.LoggerAttrs...)
+		if e.Cause != nil {
+			attrs = append(attrs, "cause", e.Cause.Error())
+		}
+		if !e.CreatedAt.IsZero() {
+			attrs = append(attrs, "created_at", e.CreatedAt)
+		}
+	}
+
+	stackKey := "goroutine_stack"
+	if config.OTelConventions {
+		stackKey = "exception.stacktrace"
+	}
+	fullStack := config.FullStack && GetHTTPCode(err) >= 500
+	if config.OTelConventions {
+		attrs = append(attrs, "exception.type", exceptionType(err), "exception.message", err.Error())
+		if isError && len(e.Stack) > 0 && !fullStack {
+			attrs = append(attrs, stackKey, string(e.Stack))
+		}
+	}
+	attrs = append(attrs, config.LoggerAttrs...)
+	if fullStack {
+		attrs = append(attrs, stackKey, goroutineStack())
 	}
+	return attrs
 }
 
 func LogErrUseLogger(logger *slog.Logger) LogErrOption {
@@ -52,3 +176,188 @@ func LogErrUseLoggerAttrs(args ...any) LogErrOption {
 		opts.LoggerAttrs = args
 	}
 }
+
+// LogErrUseSkipSentinels suppresses the record entirely for errors matching
+// any of sentinels, e.g. expected ErrNotFound on a lookup endpoint.
+func LogErrUseSkipSentinels(sentinels ...error) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.SkipSentinels = sentinels
+	}
+}
+
+// LogErrUseMaxDetails logs at most the first n key/value pairs of the
+// error's LogDetails. When details are dropped, the record gets a
+// "details_truncated" attribute.
+func LogErrUseMaxDetails(n int) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.MaxDetails = n
+	}
+}
+
+// truncateDetails keeps the first limit key/value pairs of details, counting
+// a slog.Attr as one pair, and reports whether any were dropped.
+// A limit of zero or less keeps everything.
+func truncateDetails(details []any, limit int) ([]any, bool) {
+	if limit <= 0 {
+		return details, false
+	}
+	pairs := 0
+	for i := 0; i < len(details); i++ {
+		if pairs == limit {
+			return details[:i], true
+		}
+		if _, ok := details[i].(slog.Attr); !ok {
+			i++
+		}
+		pairs++
+	}
+	return details, false
+}
+
+// LogErrUseOTelConventions adds the OpenTelemetry "exception.type" and
+// "exception.message" attributes to every record and logs stacks, both the
+// one captured by WithStack and the one added by LogErrUseFullStack, as
+// "exception.stacktrace". Other attributes keep their names.
+func LogErrUseOTelConventions(enabled bool) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.OTelConventions = enabled
+	}
+}
+
+// exceptionType names err for "exception.type": the canonical name of its
+// sentinel, such as "NOT_FOUND", or else its Go type.
+func exceptionType(err error) string {
+	if name := CanonicalName(err); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// LogErrUseDetailsGroup sets the slog group the error's LogDetails are
+// logged under, "error_details" by default. An empty name logs them as
+// top-level attributes.
+func LogErrUseDetailsGroup(name string) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.DetailsGroup = name
+	}
+}
+
+// LogErrUseThrottle emits at most one record per key every interval.
+// Suppressed records are counted and reported as a "suppressed" attribute
+// on the next emitted record for the same key.
+// Keys are kept for the lifetime of the process, so use low-cardinality keys.
+func LogErrUseThrottle(key string, every time.Duration) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.ThrottleKey = key
+		opts.ThrottleEvery = every
+	}
+}
+
+type throttleState struct {
+	last       time.Time
+	suppressed int
+}
+
+var (
+	throttleMu sync.Mutex
+	throttles  = make(map[string]*throttleState)
+)
+
+// allowLog reports whether a record for key may be emitted now, and how many
+// records were suppressed since the previous emitted one.
+func allowLog(key string, every time.Duration) (allowed bool, suppressed int) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+
+	now := Clock()
+	st, ok := throttles[key]
+	if !ok {
+		throttles[key] = &throttleState{last: now}
+		return true, 0
+	}
+	if now.Sub(st.last) < every {
+		st.suppressed++
+		return false, 0
+	}
+
+	suppressed = st.suppressed
+	st.last, st.suppressed = now, 0
+	return true, suppressed
+}
+
+// LogErrUseFullStack adds the current goroutine stack, including its ID,
+// as a "goroutine_stack" attribute when logging errors that map to a 5xx
+// status. Capturing the stack is costly, so it is disabled by default.
+func LogErrUseFullStack(enabled bool) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.FullStack = enabled
+	}
+}
+
+func goroutineStack() string {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// LogErrUseDomainLevel logs errors whose Domain is a key of levels at the
+// mapped level, e.g. to demote benign "healthcheck" errors to Debug.
+// Errors of other domains use the configured level.
+func LogErrUseDomainLevel(levels map[string]slog.Level) LogErrOption {
+	return func(opts *LogErrOptions) {
+		opts.DomainLevels = levels
+	}
+}
+
+// LogErrUseLoggers sends every record to all of the given loggers,
+// e.g. a console logger and a JSON file logger.
+func LogErrUseLoggers(loggers ...*slog.Logger) LogErrOption {
+	handlers := make(fanoutHandler, 0, len(loggers))
+	for _, l := range loggers {
+		handlers = append(handlers, l.Handler())
+	}
+	return LogErrUseLogger(slog.New(handlers))
+}
+
+// fanoutHandler is a slog.Handler passing records to several handlers.
+type fanoutHandler []slog.Handler
+
+func (f fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (f fanoutHandler) WithGroup(name string) slog.Handler {
+	out := make(fanoutHandler, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,554 @@
+package errs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/4nd3r5on/errs"
+)
+
+// newLogBuffer returns a logger writing JSON records into the returned buffer.
+func newLogBuffer() (*slog.Logger, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+}
+
+// logEntries decodes every JSON record written to buf.
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		entry := make(map[string]any)
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("decode log entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogErrUseThrottle(t *testing.T) {
+	t.Cleanup(errs.ResetThrottles)
+
+	t.Run("emits one of many rapid identical logs", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errors.New("upstream unavailable")
+
+		for range 100 {
+			errs.LogErr(context.Background(), err,
+				errs.LogErrUseLogger(logger),
+				errs.LogErrUseThrottle(t.Name(), time.Hour),
+			)
+		}
+
+		if entries := logEntries(t, buf); len(entries) != 1 {
+			t.Errorf("got %d log entries, want 1", len(entries))
+		}
+	})
+
+	t.Run("reports suppressed count once the window passes", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errors.New("upstream unavailable")
+		opts := []errs.LogErrOption{
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseThrottle(t.Name(), 20*time.Millisecond),
+		}
+
+		now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		orig := errs.Clock
+		t.Cleanup(func() { errs.Clock = orig })
+		errs.Clock = func() time.Time { return now }
+
+		for range 5 {
+			errs.LogErr(context.Background(), err, opts...)
+		}
+		now = now.Add(30 * time.Millisecond)
+		errs.LogErr(context.Background(), err, opts...)
+
+		entries := logEntries(t, buf)
+		if len(entries) != 2 {
+			t.Fatalf("got %d log entries, want 2", len(entries))
+		}
+		if got := entries[1]["suppressed"]; got != float64(4) {
+			t.Errorf("suppressed = %v, want 4", got)
+		}
+	})
+}
+
+func TestLogErr(t *testing.T) {
+	t.Run("reports cause without making it matchable", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		cause := errors.New("worker 3: out of memory")
+		err := errs.New("batch aborted", errs.WithCause(cause))
+
+		if errors.Is(err, cause) {
+			t.Error("errors.Is(err, cause) = true, want false")
+		}
+		if errors.Unwrap(err) == cause {
+			t.Error("Unwrap() returned the cause")
+		}
+
+		errs.LogErr(context.Background(), err, errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if got := entries[0]["cause"]; got != cause.Error() {
+			t.Errorf("cause = %v, want %q", got, cause.Error())
+		}
+	})
+
+	t.Run("includes creation time", func(t *testing.T) {
+		created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		useClock(t, created)
+		logger, buf := newLogBuffer()
+
+		errs.LogErr(context.Background(), errs.New("boom"), errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if got := entries[0]["created_at"]; got != created.Format(time.RFC3339) {
+			t.Errorf("created_at = %v, want %v", got, created.Format(time.RFC3339))
+		}
+	})
+}
+
+func TestLogErrUseDetailsGroup(t *testing.T) {
+	newErr := func() error {
+		return errs.New("charge failed", func(e *errs.Error) {
+			e.LogDetails = []any{"request_id", "from-details", "amount", 42}
+		})
+	}
+
+	t.Run("groups details under error_details by default", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+
+		errs.LogErr(context.Background(), newErr(),
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseLoggerAttrs("request_id", "req-1"),
+		)
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		group, ok := entries[0]["error_details"].(map[string]any)
+		if !ok {
+			t.Fatalf("error_details = %v, want object", entries[0]["error_details"])
+		}
+		if group["request_id"] != "from-details" || group["amount"] != float64(42) {
+			t.Errorf("error_details = %v, want request_id and amount", group)
+		}
+		if entries[0]["request_id"] != "req-1" {
+			t.Errorf("request_id = %v, want %q", entries[0]["request_id"], "req-1")
+		}
+	})
+
+	t.Run("uses a custom group name", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+
+		errs.LogErr(context.Background(), newErr(),
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseDetailsGroup("details"),
+		)
+
+		entries := logEntries(t, buf)
+		if _, ok := entries[0]["details"].(map[string]any); !ok {
+			t.Errorf("details = %v, want object", entries[0]["details"])
+		}
+	})
+
+	t.Run("logs details top-level without a group", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+
+		errs.LogErr(context.Background(), newErr(),
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseDetailsGroup(""),
+		)
+
+		entries := logEntries(t, buf)
+		if entries[0]["amount"] != float64(42) {
+			t.Errorf("amount = %v, want 42", entries[0]["amount"])
+		}
+	})
+}
+
+func TestLogErrUseMaxDetails(t *testing.T) {
+	t.Run("truncates oversized details", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errs.New("import failed", func(e *errs.Error) {
+			e.LogDetails = []any{"file", "users.csv", slog.Int("rows", 10000), "body", "...", "retries", 2}
+		})
+
+		errs.LogErr(context.Background(), err,
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseMaxDetails(2),
+		)
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		details, _ := entries[0]["error_details"].(map[string]any)
+		if len(details) != 2 || details["file"] != "users.csv" || details["rows"] != float64(10000) {
+			t.Errorf("error_details = %v, want file and rows only", details)
+		}
+		if entries[0]["details_truncated"] != true {
+			t.Errorf("details_truncated = %v, want true", entries[0]["details_truncated"])
+		}
+	})
+
+	t.Run("keeps details within the limit", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errs.New("import failed", func(e *errs.Error) {
+			e.LogDetails = []any{"file", "users.csv"}
+		})
+
+		errs.LogErr(context.Background(), err,
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseMaxDetails(1),
+		)
+
+		entries := logEntries(t, buf)
+		if _, ok := entries[0]["details_truncated"]; ok {
+			t.Errorf("details_truncated set for details within the limit: %v", entries[0])
+		}
+	})
+}
+
+func TestLogErrUseSkipSentinels(t *testing.T) {
+	logger, buf := newLogBuffer()
+	opts := []errs.LogErrOption{
+		errs.LogErrUseLogger(logger),
+		errs.LogErrUseSkipSentinels(errs.ErrNotFound, errs.ErrExists),
+	}
+
+	errs.LogErr(context.Background(), errs.Mark(errors.New("no row"), errs.ErrNotFound), opts...)
+	errs.LogErr(context.Background(), errs.Mark(errors.New("db down"), errs.ErrInternal), opts...)
+
+	entries := logEntries(t, buf)
+	if len(entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(entries))
+	}
+	if entries[0]["msg"] != "db down" {
+		t.Errorf("msg = %v, want %q", entries[0]["msg"], "db down")
+	}
+}
+
+func TestWrapLog(t *testing.T) {
+	t.Run("wraps and logs the error", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		base := errors.New("disk full")
+
+		err := errs.WrapLog(context.Background(), base, "save upload",
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseLogLevel(slog.LevelWarn),
+		)
+
+		if want := "save upload: disk full"; err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err.Error(), want)
+		}
+		if !errors.Is(err, base) {
+			t.Error("errors.Is(err, base) = false, want true")
+		}
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if entries[0]["msg"] != "save upload: disk full" {
+			t.Errorf("logged msg = %v, want wrapped message", entries[0]["msg"])
+		}
+		if entries[0]["level"] != slog.LevelWarn.String() {
+			t.Errorf("logged level = %v, want %v", entries[0]["level"], slog.LevelWarn)
+		}
+	})
+
+	t.Run("returns nil without logging when err is nil", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+
+		if err := errs.WrapLog(context.Background(), nil, "save upload", errs.LogErrUseLogger(logger)); err != nil {
+			t.Errorf("WrapLog(nil) = %v, want nil", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("unexpected log output: %s", buf.String())
+		}
+	})
+}
+
+func TestLogErrUseFullStack(t *testing.T) {
+	internal := errs.Mark(errors.New("nil pointer"), errs.ErrInternal)
+
+	tests := []struct {
+		name    string
+		err     error
+		enabled bool
+		want    bool
+	}{
+		{"enabled for internal error", internal, true, true},
+		{"disabled for internal error", internal, false, false},
+		{"enabled for client error", errs.Mark(errors.New("no row"), errs.ErrNotFound), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newLogBuffer()
+			errs.LogErr(context.Background(), tt.err,
+				errs.LogErrUseLogger(logger),
+				errs.LogErrUseFullStack(tt.enabled),
+			)
+
+			entries := logEntries(t, buf)
+			if len(entries) != 1 {
+				t.Fatalf("got %d log entries, want 1", len(entries))
+			}
+			stack, ok := entries[0]["goroutine_stack"].(string)
+			if ok != tt.want {
+				t.Fatalf("goroutine_stack present = %v, want %v", ok, tt.want)
+			}
+			if ok && !strings.Contains(stack, "TestLogErrUseFullStack") {
+				t.Errorf("goroutine_stack does not contain the caller: %s", stack)
+			}
+		})
+	}
+}
+
+func TestLogAtLevel(t *testing.T) {
+	// The error's own level and the configured one are both overridden.
+	err := errs.New("cache refresh failed", errs.WithLogLevel(slog.LevelError))
+
+	tests := []struct {
+		name string
+		log  func(context.Context, error, ...errs.LogErrOption)
+		want string
+	}{
+		{"LogWarn", errs.LogWarn, "WARN"},
+		{"LogInfo", errs.LogInfo, "INFO"},
+		{"LogDebug", errs.LogDebug, "DEBUG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, buf := newLogBuffer()
+			tt.log(context.Background(), err,
+				errs.LogErrUseLogger(logger),
+				errs.LogErrUseLogLevel(slog.LevelError),
+			)
+
+			entries := logEntries(t, buf)
+			if len(entries) != 1 {
+				t.Fatalf("got %d log entries, want 1", len(entries))
+			}
+			if got := entries[0]["level"]; got != tt.want {
+				t.Errorf("level = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("ignores nil", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		errs.LogWarn(context.Background(), nil, errs.LogErrUseLogger(logger))
+		if buf.Len() != 0 {
+			t.Errorf("logged %q for nil error", buf.String())
+		}
+	})
+}
+
+func TestBuildLogAttrs(t *testing.T) {
+	created := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	useClock(t, created)
+
+	err := errs.New("charge card", func(e *errs.Error) {
+		e.Domain = "billing"
+		e.LogDetails = []any{"customer", 42, "attempt", 2}
+	})
+
+	t.Run("returns the attributes LogErr emits", func(t *testing.T) {
+		opts := errs.DefaultLogErrOptions
+		opts.LoggerAttrs = []any{"request_id", "r-1"}
+
+		got := errs.BuildLogAttrs(err, &opts)
+		want := []any{
+			slog.Group("error_details", "customer", 42, "attempt", 2),
+			"domain", "billing",
+			"created_at", created,
+			"request_id", "r-1",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildLogAttrs() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("uses DefaultLogErrOptions for nil options", func(t *testing.T) {
+		got := errs.BuildLogAttrs(err, nil)
+		if len(got) == 0 || !reflect.DeepEqual(got[0], slog.Group("error_details", "customer", 42, "attempt", 2)) {
+			t.Errorf("BuildLogAttrs() = %v, want details in the error_details group", got)
+		}
+	})
+
+	t.Run("returns nil for nil error", func(t *testing.T) {
+		if got := errs.BuildLogAttrs(nil, nil); got != nil {
+			t.Errorf("BuildLogAttrs(nil) = %v, want nil", got)
+		}
+	})
+}
+
+func TestLogErrUseOTelConventions(t *testing.T) {
+	t.Run("uses exception attribute keys", func(t *testing.T) {
+		err := errs.Mark(errors.New("no row for id 7"), errs.ErrNotFound, errs.WithStack())
+
+		logger, buf := newLogBuffer()
+		errs.LogErr(context.Background(), err,
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseOTelConventions(true),
+		)
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if got := entries[0]["exception.type"]; got != "NOT_FOUND" {
+			t.Errorf("exception.type = %v, want NOT_FOUND", got)
+		}
+		if got := entries[0]["exception.message"]; got != err.Error() {
+			t.Errorf("exception.message = %v, want %q", got, err.Error())
+		}
+		if _, ok := entries[0]["exception.stacktrace"].(string); !ok {
+			t.Error("exception.stacktrace missing")
+		}
+	})
+
+	t.Run("renames the full stack", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		errs.LogErr(context.Background(), errors.New("nil pointer"),
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseFullStack(true),
+			errs.LogErrUseOTelConventions(true),
+		)
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if got := entries[0]["exception.type"]; got != "*errors.errorString" {
+			t.Errorf("exception.type = %v, want *errors.errorString", got)
+		}
+		if _, ok := entries[0]["exception.stacktrace"].(string); !ok {
+			t.Error("exception.stacktrace missing")
+		}
+		if _, ok := entries[0]["goroutine_stack"]; ok {
+			t.Error("goroutine_stack present, want it renamed")
+		}
+	})
+
+	t.Run("keeps default naming when disabled", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		errs.LogErr(context.Background(), errors.New("boom"), errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if _, ok := entries[0]["exception.type"]; ok {
+			t.Error("exception.type present, want default naming")
+		}
+	})
+}
+
+func TestLogErrUseDomainLevel(t *testing.T) {
+	levels := map[string]slog.Level{"healthcheck": slog.LevelDebug}
+
+	tests := []struct {
+		domain string
+		want   slog.Level
+	}{
+		{"healthcheck", slog.LevelDebug},
+		{"billing", slog.LevelError},
+		{"", slog.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			logger, buf := newLogBuffer()
+			err := errs.New("probe failed", func(e *errs.Error) { e.Domain = tt.domain })
+
+			errs.LogErr(context.Background(), err,
+				errs.LogErrUseLogger(logger),
+				errs.LogErrUseDomainLevel(levels),
+			)
+
+			entries := logEntries(t, buf)
+			if len(entries) != 1 {
+				t.Fatalf("got %d log entries, want 1", len(entries))
+			}
+			if entries[0]["level"] != tt.want.String() {
+				t.Errorf("level = %v, want %v", entries[0]["level"], tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLogLevel(t *testing.T) {
+	t.Run("pinned level overrides default", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errs.New("cache miss", errs.WithLogLevel(slog.LevelDebug))
+
+		errs.LogErr(context.Background(), err, errs.LogErrUseLogger(logger))
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if entries[0]["level"] != slog.LevelDebug.String() {
+			t.Errorf("level = %v, want %v", entries[0]["level"], slog.LevelDebug)
+		}
+	})
+
+	t.Run("pinned level survives wrapping", func(t *testing.T) {
+		logger, buf := newLogBuffer()
+		err := errs.Wrap(errs.New("cache miss", errs.WithLogLevel(slog.LevelWarn)), "get user")
+
+		errs.LogErr(context.Background(), err,
+			errs.LogErrUseLogger(logger),
+			errs.LogErrUseLogLevel(slog.LevelError),
+		)
+
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Fatalf("got %d log entries, want 1", len(entries))
+		}
+		if entries[0]["level"] != slog.LevelWarn.String() {
+			t.Errorf("level = %v, want %v", entries[0]["level"], slog.LevelWarn)
+		}
+	})
+}
+
+func TestLogErrUseLoggers(t *testing.T) {
+	console, consoleBuf := newLogBuffer()
+	file, fileBuf := newLogBuffer()
+	err := errs.New("boom", func(e *errs.Error) { e.Domain = "jobs" })
+
+	errs.LogErr(context.Background(), err, errs.LogErrUseLoggers(console, file))
+
+	for name, buf := range map[string]*bytes.Buffer{"console": consoleBuf, "file": fileBuf} {
+		entries := logEntries(t, buf)
+		if len(entries) != 1 {
+			t.Errorf("%s got %d log entries, want 1", name, len(entries))
+			continue
+		}
+		if entries[0]["msg"] != "boom" || entries[0]["domain"] != "jobs" {
+			t.Errorf("%s entry = %v, want msg and domain", name, entries[0])
+		}
+	}
+}
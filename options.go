@@ -0,0 +1,184 @@
+package errs
+
+import (
+	"log/slog"
+	"net/http"
+	"path"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Option can be provided in args to New and Newf
+// to change error's parameters
+type Option func(*Error)
+
+// WithHeader adds a header that HandleHTTP sets on the response,
+// e.g. WWW-Authenticate for ErrUnauthorized.
+func WithHeader(key, value string) Option {
+	return func(e *Error) {
+		if e.Headers == nil {
+			e.Headers = make(http.Header)
+		}
+		e.Headers.Add(key, value)
+	}
+}
+
+// WithMeta adds a metadata entry that HandleHTTPErr sends as a response
+// trailer when HandleHTTPErrOpts.IncludeMeta is set.
+func WithMeta(key, value string) Option {
+	return func(e *Error) {
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]string)
+		}
+		e.Metadata[key] = value
+	}
+}
+
+// WithStack captures the current goroutine stack into the error.
+// It is printed by the %+v verb.
+func WithStack() Option {
+	return func(e *Error) {
+		e.Stack = debug.Stack()
+	}
+}
+
+// WithDetailsFrom appends the details of src, as reported by GetAllDetails,
+// to the error's LogDetails. Use it to keep observability when translating
+// a foreign error into a new *Error.
+func WithDetailsFrom(src error) Option {
+	return func(e *Error) {
+		e.LogDetails = append(e.LogDetails, GetAllDetails(src)...)
+	}
+}
+
+// WithInternal sets the underlying cause of the error, replacing the
+// message built by New or Newf. Unwrap returns cause.
+func WithInternal(cause error) Option {
+	return func(e *Error) {
+		e.Internal = cause
+	}
+}
+
+// WithReason sets the machine-readable reason of the error.
+func WithReason(reason string) Option {
+	return func(e *Error) {
+		e.Reason = reason
+	}
+}
+
+// WithLogLevel pins the level the error is logged at, regardless of
+// the level configured for LogErr or derived from the HTTP status.
+func WithLogLevel(level slog.Level) Option {
+	return func(e *Error) {
+		e.LogLevel = &level
+	}
+}
+
+// WithCode sets the machine-readable code of the error.
+func WithCode(code string) Option {
+	return func(e *Error) {
+		e.Code = code
+	}
+}
+
+// WithExitCode sets the exit code HandleCLI returns for the error.
+func WithExitCode(code int) Option {
+	return func(e *Error) {
+		e.ExitCode = code
+	}
+}
+
+// WithCause records cause for logging without making it part of the
+// errors.Is/As chain. See Error.Cause.
+func WithCause(cause error) Option {
+	return func(e *Error) {
+		e.Cause = cause
+	}
+}
+
+// WithMarkers marks the error with markers at construction time,
+// e.g. New("boom", WithMarkers(ErrInternal)). Nil markers are ignored.
+func WithMarkers(markers ...error) Option {
+	return func(e *Error) {
+		// Full slice expression forces a copy, so a slice shared with
+		// a wrapped error is never appended to in place.
+		e.Markers = e.Markers[:len(e.Markers):len(e.Markers)]
+		for _, marker := range markers {
+			if marker != nil {
+				e.Markers = append(e.Markers, marker)
+			}
+		}
+	}
+}
+
+// AddMarker marks the error being built with marker, e.g. to add a marker
+// while wrapping: Wrap(err, "load user", AddMarker(ErrNotFound)).
+// Inherited markers are kept and the wrapped error is never modified.
+// A nil marker is ignored.
+func AddMarker(marker error) Option {
+	return func(e *Error) {
+		if marker == nil {
+			return
+		}
+		e.Markers = append(e.Markers[:len(e.Markers):len(e.Markers)], marker)
+	}
+}
+
+// WrapResetVisibility makes the error private regardless of the visibility
+// inherited from a wrapped *Error, e.g. at a trust boundary:
+// Wrap(err, "call billing", WrapResetVisibility()).
+func WrapResetVisibility() Option {
+	return func(e *Error) {
+		e.ExposeInternal = false
+	}
+}
+
+// WithHint adds a user-facing hint on how to resolve the error.
+// The hint may be a message key translated by HandleHTTPErrOpts.Localizer.
+func WithHint(hint string) Option {
+	return func(e *Error) {
+		e.Hints = append(e.Hints, hint)
+	}
+}
+
+// WithUserDetailsFunc defers computing the user details until a response
+// is rendered: fn is called at most once, by Sanitize, and only if
+// UserDetails is nil. Errors that are only logged never call it.
+func WithUserDetailsFunc(fn func() any) Option {
+	return func(e *Error) {
+		e.lazyUserDetails = &lazyDetails{fn: fn}
+	}
+}
+
+// WithAutoDomain sets Domain to the calling function, as "package.Function",
+// unless a domain is already set. The caller is the first function outside
+// this package, so it also works through helpers like MarkIf.
+// Resolving the caller has a cost, hence it is opt-in per call.
+func WithAutoDomain() Option {
+	return func(e *Error) {
+		if e.Domain == "" {
+			e.Domain = callerName()
+		}
+	}
+}
+
+var pkgPrefix = reflect.TypeFor[Error]().PkgPath() + "."
+
+// callerName returns the name of the closest function on the stack
+// that does not belong to this package.
+func callerName() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) {
+			return path.Base(frame.Function)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
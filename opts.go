@@ -1,11 +1,45 @@
 package errs
 
-import "log/slog"
+import (
+	"log/slog"
+	"time"
+)
 
 type LogErrOptions struct {
 	Logger      *slog.Logger
 	LogLevel    slog.Level
 	LoggerAttrs []any
+
+	// ThrottleKey groups records for throttling. Empty disables throttling.
+	ThrottleKey string
+	// ThrottleEvery is the minimum interval between records sharing ThrottleKey.
+	ThrottleEvery time.Duration
+
+	// FullStack adds the current goroutine stack to records of errors
+	// mapping to a 5xx status.
+	FullStack bool
+
+	// DomainLevels overrides LogLevel for errors of the given domains.
+	DomainLevels map[string]slog.Level
+
+	// SkipSentinels suppresses logging of errors matching any of them.
+	SkipSentinels []error
+
+	// MaxDetails caps the logged LogDetails to that many key/value pairs.
+	// Zero means no limit.
+	MaxDetails int
+
+	// DetailsGroup is the slog group holding the error's LogDetails,
+	// keeping them apart from LoggerAttrs. Empty logs them top-level.
+	DetailsGroup string
+
+	// OTelConventions names the logged attributes after the OpenTelemetry
+	// exception semantic conventions.
+	OTelConventions bool
+
+	// fixedLevel makes LogLevel take precedence over DomainLevels and
+	// the error's own LogLevel. Set by LogWarn and its variants.
+	fixedLevel bool
 }
 
 type LogErrOption func(*LogErrOptions)
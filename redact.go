@@ -0,0 +1,193 @@
+package errs
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// RedactedPlaceholder replaces the value of string fields tagged
+// `errs:"secret"`. Secret fields of other types are zeroed.
+const RedactedPlaceholder = "[REDACTED]"
+
+// redact returns a copy of v in which every exported struct field tagged
+// `errs:"secret"` is redacted, following pointers, interfaces, slices,
+// arrays, maps and embedded structs, whose exported fields encoding/json
+// promotes even when the embedded type is unexported. v itself is never modified. Values whose type cannot
+// hold a secret field are returned as is. Cycles are preserved in the copy.
+func redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !mayHoldSecret(rv.Type()) {
+		return v
+	}
+	r := redactor{copies: make(map[redactKey]reflect.Value)}
+	return r.value(rv).Interface()
+}
+
+// redactKey identifies an already copied pointer, map or slice.
+type redactKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+type redactor struct {
+	copies map[redactKey]reflect.Value
+}
+
+func (r *redactor) value(v reflect.Value) reflect.Value {
+	if !mayHoldSecret(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		key := redactKey{v.Type(), v.Pointer()}
+		if c, ok := r.copies[key]; ok {
+			return c
+		}
+		c := reflect.New(v.Type().Elem())
+		r.copies[key] = c
+		c.Elem().Set(r.value(v.Elem()))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(r.value(v.Elem()))
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := range v.NumField() {
+			f := v.Type().Field(i)
+			if !walkableField(f) {
+				continue
+			}
+			field := c.Field(i)
+			if !f.IsExported() {
+				// c is addressable, so an embedded unexported field can be
+				// made settable through its address.
+				field = reflect.NewAt(f.Type, unsafe.Pointer(field.UnsafeAddr())).Elem()
+			}
+			if isSecretField(f) {
+				field.Set(redactedValue(f.Type))
+				continue
+			}
+			field.Set(r.value(field))
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		key := redactKey{v.Type(), v.Pointer()}
+		if c, ok := r.copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		r.copies[key] = c
+		for i := range v.Len() {
+			c.Index(i).Set(r.value(v.Index(i)))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			c.Index(i).Set(r.value(v.Index(i)))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := redactKey{v.Type(), v.Pointer()}
+		if c, ok := r.copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		r.copies[key] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), r.value(iter.Value()))
+		}
+		return c
+	}
+	return v
+}
+
+func isSecretField(f reflect.StructField) bool {
+	return slices.Contains(strings.Split(f.Tag.Get("errs"), ","), "secret")
+}
+
+// walkableField reports whether f can expose data when encoded: exported
+// fields, and embedded structs or struct pointers of unexported types.
+func walkableField(f reflect.StructField) bool {
+	if f.IsExported() {
+		return true
+	}
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return f.Anonymous && t.Kind() == reflect.Struct
+}
+
+func redactedValue(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(RedactedPlaceholder).Convert(t)
+	}
+	return reflect.Zero(t)
+}
+
+// secretTypes caches mayHoldSecret by reflect.Type.
+var secretTypes sync.Map
+
+// mayHoldSecret reports whether a value of type t can contain a field
+// tagged secret. Interfaces always may, as their dynamic type is unknown.
+func mayHoldSecret(t reflect.Type) bool {
+	if cached, ok := secretTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	has := typeMayHoldSecret(t, make(map[reflect.Type]bool))
+	secretTypes.Store(t, has)
+	return has
+}
+
+func typeMayHoldSecret(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return typeMayHoldSecret(t.Elem(), seen)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !walkableField(f) {
+				continue
+			}
+			if isSecretField(f) || typeMayHoldSecret(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
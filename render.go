@@ -0,0 +1,127 @@
+package errs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ResponseRenderer writes an error response with the given status.
+// Headers attached to the error are already set on w.
+type ResponseRenderer interface {
+	Render(w http.ResponseWriter, resp ErrorHTTPResponse, status int) error
+}
+
+// JSONRenderer renders the response as a JSON object. It is the default.
+type JSONRenderer struct {
+	FieldNaming FieldNaming
+	FieldNames  ResponseFieldNames
+}
+
+// Render implements ResponseRenderer.
+// If the body cannot be encoded, a bare 500 is written instead.
+func (j JSONRenderer) Render(w http.ResponseWriter, resp ErrorHTTPResponse, status int) error {
+	body, err := marshalResponse(resp, j.FieldNaming, j.FieldNames)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(body); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// TextRenderer renders the response as a plain text "status: message" line.
+type TextRenderer struct{}
+
+// Render implements ResponseRenderer.
+func (TextRenderer) Render(w http.ResponseWriter, resp ErrorHTTPResponse, status int) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if _, err := fmt.Fprintf(w, "%d: %s\n", status, resp.Error); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// marshalResponse encodes body with its keys in the given casing, the
+// top-level ones renamed by names, followed by the Extra fields.
+func marshalResponse(body ErrorHTTPResponse, naming FieldNaming, names ResponseFieldNames) ([]byte, error) {
+	if naming == FieldNamingSnake && names == (ResponseFieldNames{}) && len(body.Extra) == 0 {
+		return json.Marshal(body)
+	}
+
+	key := func(name, override string) string {
+		if override != "" {
+			return override
+		}
+		if naming == FieldNamingCamel {
+			return snakeToCamel(name)
+		}
+		return name
+	}
+	fields := map[string]any{key("error", names.Error): body.Error}
+	if body.Code != "" {
+		fields[key("code", names.Code)] = body.Code
+	}
+	if body.Details != nil {
+		fields[key("details", names.Details)] = body.Details
+	}
+	if len(body.Hints) > 0 {
+		fields[key("hints", names.Hints)] = body.Hints
+	}
+	if body.ErrorInfo != nil {
+		fields[key("error_info", "")] = body.ErrorInfo
+	}
+	if body.Stack != "" {
+		fields[key("stack", "")] = body.Stack
+	}
+	if body.Debug != nil {
+		var debug any = body.Debug
+		if naming == FieldNamingCamel {
+			debug = errorDebugCamel(*body.Debug)
+		}
+		fields[key("debug", "")] = debug
+	}
+	for k, v := range body.Extra {
+		if _, ok := fields[k]; ok || reservedResponseKey(k) {
+			continue
+		}
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+// errorDebugCamel is ErrorDebug with camelCase keys. The keys of ErrorInfo
+// are single words, the same in either casing.
+type errorDebugCamel struct {
+	Chain      []string `json:"chain"`
+	Stack      string   `json:"stack,omitempty"`
+	LogDetails []any    `json:"logDetails,omitempty"`
+}
+
+// reservedResponseKey reports whether key names a field of
+// ErrorHTTPResponse, in either casing, even if it is omitted from the body.
+func reservedResponseKey(key string) bool {
+	switch key {
+	case "error", "code", "details", "hints", "error_info", "errorInfo", "stack", "debug":
+		return true
+	}
+	return false
+}
+
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,47 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestTextRenderer(t *testing.T) {
+	err := errs.Mark(errors.New("no row for id 7"), errs.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/items/7", nil)
+	errs.HandleHTTPErr(context.Background(), w, r, err, &errs.HandleHTTPErrOpts{
+		LogOptions: []errs.LogErrOption{discard},
+		Renderer:   errs.TextRenderer{},
+	})
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want text/plain", got)
+	}
+	if want := "404: The requested resource was not found\n"; w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	t.Run("renders foreign errors as JSON", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/items", nil)
+		errs.HandleHTTP(context.Background(), w, r, errors.New("boom"), discard)
+
+		if got := w.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+		if want := `{"error":"Internal Server Error"}`; w.Body.String() != want {
+			t.Errorf("body = %s, want %s", w.Body.String(), want)
+		}
+	})
+}
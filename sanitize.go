@@ -0,0 +1,81 @@
+package errs
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+)
+
+// SafeDetails is implemented by UserDetails values that control their public
+// projection. Sanitize replaces such details with the result of SafeView,
+// so private fields never reach the client.
+type SafeDetails interface {
+	SafeView() any
+}
+
+// EffectiveSafeMessage returns the user-facing message of err, as sent by
+// HandleHTTPErr: SafeMessage if set, the internal message if ExposeInternal
+// is set, otherwise the canonical message registered for the matched
+// sentinel, falling back to the HTTP status text.
+// Returns an empty string if err is nil.
+func EffectiveSafeMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		if e.SafeMessage != "" {
+			return e.SafeMessage
+		}
+		if e.ExposeInternal {
+			return e.Internal.Error()
+		}
+	}
+	if canonical, ok := getCanonicalMessage(err); ok {
+		return canonical
+	}
+	return http.StatusText(GetHTTPCode(err))
+}
+
+// Sanitize returns a copy of err that is safe to send to a client.
+//
+// The internal message is replaced by the user-facing one,
+// see EffectiveSafeMessage.
+// LogDetails are dropped and UserDetails implementing SafeDetails are replaced
+// by their SafeView. Struct fields tagged `errs:"secret"` in UserDetails
+// are redacted. Markers, UserDetails, Hints, Domain, Reason, Code, Headers, Metadata
+// and CreatedAt are kept, so GetHTTPCode resolves the same status for the copy.
+// Returns nil if err is nil.
+func Sanitize(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	message := EffectiveSafeMessage(err)
+	safe := &Error{LogDetails: make([]any, 0)}
+
+	var e *Error
+	if errors.As(err, &e) {
+		safe.UserDetails = e.userDetails()
+		if sd, ok := safe.UserDetails.(SafeDetails); ok {
+			safe.UserDetails = sd.SafeView()
+		}
+		safe.UserDetails = redact(safe.UserDetails)
+		safe.Hints = GetAllHints(err)
+		safe.Domain = e.Domain
+		safe.Reason = e.Reason
+		safe.Code = e.Code
+		safe.CreatedAt = e.CreatedAt
+		safe.Headers = e.Headers.Clone()
+		safe.Metadata = maps.Clone(e.Metadata)
+		safe.Markers = append(safe.Markers, e.Markers...)
+	}
+	if sentinel := canonicalSentinel(err); sentinel != nil {
+		safe.Markers = appendMarker(safe.Markers, sentinel)
+	}
+
+	safe.Internal = errors.New(message)
+	safe.SafeMessage = message
+	return safe
+}
@@ -0,0 +1,291 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+func TestSanitize(t *testing.T) {
+	t.Run("returns nil when err is nil", func(t *testing.T) {
+		if got := errs.Sanitize(nil); got != nil {
+			t.Errorf("Sanitize(nil) = %v, want nil", got)
+		}
+	})
+
+	t.Run("removes internal message and log details", func(t *testing.T) {
+		err := errs.Mark(errors.New("user 7 token=abc123 revoked"), errs.ErrPermissionDenied, func(e *errs.Error) {
+			e.SafeMessage = "Access revoked"
+			e.UserDetails = map[string]any{"reason": "revoked"}
+			e.LogDetails = []any{"token", "abc123"}
+		})
+
+		safe := errs.Sanitize(err)
+
+		if strings.Contains(fmt.Sprintf("%+v", safe), "abc123") {
+			t.Errorf("sanitized error leaks secret: %+v", safe)
+		}
+		if safe.Error() != "Access revoked" {
+			t.Errorf("Error() = %q, want %q", safe.Error(), "Access revoked")
+		}
+		if len(safe.LogDetails) != 0 {
+			t.Errorf("LogDetails = %v, want empty", safe.LogDetails)
+		}
+		if safe.UserDetails == nil {
+			t.Error("UserDetails dropped, want kept")
+		}
+		if got := errs.GetHTTPCode(safe); got != 403 {
+			t.Errorf("GetHTTPCode(safe) = %d, want 403", got)
+		}
+	})
+
+	t.Run("falls back to canonical message", func(t *testing.T) {
+		safe := errs.Sanitize(errs.Wrap(errs.ErrNotFound, "select user 7"))
+
+		if safe.Error() != "The requested resource was not found" {
+			t.Errorf("Error() = %q, want canonical message", safe.Error())
+		}
+		if got := errs.GetHTTPCode(safe); got != 404 {
+			t.Errorf("GetHTTPCode(safe) = %d, want 404", got)
+		}
+	})
+
+	t.Run("falls back to status text for unknown errors", func(t *testing.T) {
+		safe := errs.Sanitize(errors.New("panic: nil map"))
+
+		if safe.Error() != "Internal Server Error" {
+			t.Errorf("Error() = %q, want %q", safe.Error(), "Internal Server Error")
+		}
+	})
+}
+
+type accountDetails struct {
+	AccountID string
+	APIKey    string
+}
+
+func (a accountDetails) SafeView() any {
+	return map[string]string{"account_id": a.AccountID}
+}
+
+func TestSafeDetails(t *testing.T) {
+	err := errs.Mark(errors.New("account locked"), errs.ErrPermissionDenied, func(e *errs.Error) {
+		e.UserDetails = accountDetails{AccountID: "acc_1", APIKey: "sk_live_secret"}
+	})
+
+	t.Run("Sanitize uses the safe view", func(t *testing.T) {
+		safe := errs.Sanitize(err)
+
+		view, ok := safe.UserDetails.(map[string]string)
+		if !ok {
+			t.Fatalf("UserDetails = %T, want safe view", safe.UserDetails)
+		}
+		if view["account_id"] != "acc_1" {
+			t.Errorf("account_id = %q, want %q", view["account_id"], "acc_1")
+		}
+	})
+
+	t.Run("HandleHTTP serializes the safe view", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/account", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		if strings.Contains(w.Body.String(), "sk_live_secret") {
+			t.Errorf("body leaks secret: %s", w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"details":{"account_id":"acc_1"}`) {
+			t.Errorf("body = %s, want safe details", w.Body.String())
+		}
+	})
+}
+
+func TestEffectiveSafeMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "safe message",
+			err: errs.Mark(errors.New("no row"), errs.ErrNotFound, func(e *errs.Error) {
+				e.SafeMessage = "User not found"
+			}),
+			want: "User not found",
+		},
+		{
+			name: "exposed internal message",
+			err: errs.Mark(errors.New("email is taken"), errs.ErrExists, func(e *errs.Error) {
+				e.ExposeInternal = true
+			}),
+			want: "email is taken",
+		},
+		{
+			name: "canonical message",
+			err:  errs.Mark(errors.New("no row"), errs.ErrNotFound),
+			want: "The requested resource was not found",
+		},
+		{
+			name: "status deciding sentinel",
+			err:  errs.MarkMany(errors.New("nil map"), errs.ErrNotFound, errs.ErrInternal),
+			want: "An internal error occurred",
+		},
+		{
+			name: "status text",
+			err:  errors.New("db connection reset"),
+			want: "Internal Server Error",
+		},
+		{
+			name: "nil",
+			err:  nil,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errs.EffectiveSafeMessage(tt.err); got != tt.want {
+				t.Errorf("EffectiveSafeMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("status text when the deciding sentinel has no message", func(t *testing.T) {
+		orig := errs.HTTPCodeMappings
+		t.Cleanup(func() { errs.HTTPCodeMappings = orig })
+		errMaintenance := errors.New("maintenance")
+		errs.HTTPCodeMappings = append([]errs.HTTPCodeMapping{
+			{Sentinel: errMaintenance, Status: http.StatusServiceUnavailable},
+		}, orig...)
+
+		err := errs.MarkMany(errors.New("read only"), errs.ErrNotFound, errMaintenance)
+		if got, want := errs.EffectiveSafeMessage(err), "Service Unavailable"; got != want {
+			t.Errorf("EffectiveSafeMessage() = %q, want %q", got, want)
+		}
+	})
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password" errs:"secret"`
+	Token    []byte `json:"token,omitempty" errs:"secret"`
+	Parent   *credentials
+}
+
+type loginForm struct {
+	credentials
+	Name string
+}
+
+type loginFormPtr struct {
+	*credentials
+	Name string
+}
+
+func TestSecretRedaction(t *testing.T) {
+	newErr := func() (error, *credentials) {
+		creds := &credentials{
+			Username: "alice",
+			Password: "hunter2",
+			Token:    []byte("tok"),
+			Parent:   &credentials{Username: "root", Password: "toor"},
+		}
+		err := errs.Mark(errors.New("login failed"), errs.ErrUnauthorized, func(e *errs.Error) {
+			e.UserDetails = map[string]any{"account": creds}
+			e.LogDetails = []any{"credentials", creds}
+		})
+		return err, creds
+	}
+
+	t.Run("redacts user details in response", func(t *testing.T) {
+		err, creds := newErr()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/login", nil)
+
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		body := w.Body.String()
+		if strings.Contains(body, "hunter2") || strings.Contains(body, "toor") {
+			t.Errorf("response leaks password: %s", body)
+		}
+		if !strings.Contains(body, errs.RedactedPlaceholder) || !strings.Contains(body, "root") {
+			t.Errorf("response = %s, want redacted password and kept username", body)
+		}
+		if creds.Password != "hunter2" || creds.Parent.Password != "toor" {
+			t.Error("redaction modified the original details")
+		}
+	})
+
+	t.Run("redacts log details", func(t *testing.T) {
+		err, creds := newErr()
+		logger, buf := newLogBuffer()
+
+		errs.LogErr(context.Background(), err, errs.LogErrUseLogger(logger))
+
+		logged := buf.String()
+		if strings.Contains(logged, "hunter2") || strings.Contains(logged, "dG9r") {
+			t.Errorf("log leaks secret: %s", logged)
+		}
+		if !strings.Contains(logged, "alice") {
+			t.Errorf("log = %s, want username kept", logged)
+		}
+		if creds.Password != "hunter2" || string(creds.Token) != "tok" {
+			t.Error("redaction modified the original details")
+		}
+	})
+
+	t.Run("redacts fields promoted from unexported embedded structs", func(t *testing.T) {
+		forms := map[string]any{
+			"value":   loginForm{credentials: credentials{Username: "alice", Password: "hunter2"}, Name: "web"},
+			"pointer": loginFormPtr{credentials: &credentials{Username: "alice", Password: "hunter2"}, Name: "web"},
+		}
+		for name, form := range forms {
+			t.Run(name, func(t *testing.T) {
+				err := errs.Mark(errors.New("login failed"), errs.ErrUnauthorized, func(e *errs.Error) {
+					e.UserDetails = form
+					e.LogDetails = []any{"form", form}
+				})
+				w := httptest.NewRecorder()
+				r := httptest.NewRequest(http.MethodPost, "/login", nil)
+				logger, buf := newLogBuffer()
+
+				errs.HandleHTTP(context.Background(), w, r, err, discard)
+				errs.LogErr(context.Background(), err, errs.LogErrUseLogger(logger))
+
+				if body := w.Body.String(); strings.Contains(body, "hunter2") || !strings.Contains(body, "alice") {
+					t.Errorf("response = %s, want redacted password and kept username", body)
+				}
+				if logged := buf.String(); strings.Contains(logged, "hunter2") || !strings.Contains(logged, "alice") {
+					t.Errorf("log = %s, want redacted password and kept username", logged)
+				}
+			})
+		}
+		if forms["pointer"].(loginFormPtr).Password != "hunter2" {
+			t.Error("redaction modified the original details")
+		}
+	})
+
+	t.Run("handles cycles", func(t *testing.T) {
+		creds := &credentials{Username: "alice", Password: "hunter2"}
+		creds.Parent = creds
+		err := errs.Mark(errors.New("login failed"), errs.ErrUnauthorized, func(e *errs.Error) {
+			e.UserDetails = creds
+		})
+
+		got, ok := errs.Sanitize(err).UserDetails.(*credentials)
+		if !ok {
+			t.Fatalf("UserDetails = %T, want *credentials", errs.Sanitize(err).UserDetails)
+		}
+		if got.Password != errs.RedactedPlaceholder || got.Parent != got {
+			t.Errorf("redacted = %+v, want redacted password and preserved cycle", got)
+		}
+		if creds.Password != "hunter2" {
+			t.Error("redaction modified the original details")
+		}
+	})
+}
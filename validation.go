@@ -0,0 +1,48 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidationFieldError is the per-field error of validation libraries such
+// as github.com/go-playground/validator, whose FieldError satisfies it.
+type ValidationFieldError interface {
+	Field() string
+	Tag() string
+	Param() string
+}
+
+// FieldError describes one invalid field in the response details.
+type FieldError struct {
+	Field string `json:"field"`
+	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
+}
+
+// FromValidationErrors converts failed validations, e.g. a
+// validator.ValidationErrors, into an error marked ErrInvalidArgument whose
+// UserDetails is a []FieldError, one entry per field.
+// Returns nil if fields is empty.
+func FromValidationErrors[F ValidationFieldError](fields []F) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	details := make([]FieldError, 0, len(fields))
+	msgs := make([]string, 0, len(fields))
+	for _, f := range fields {
+		details = append(details, FieldError{Field: f.Field(), Tag: f.Tag(), Param: f.Param()})
+		msgs = append(msgs, fmt.Sprintf("field %q failed on %q", f.Field(), f.Tag()))
+	}
+
+	e := &Error{
+		Internal:    errors.New("validation failed: " + strings.Join(msgs, "; ")),
+		UserDetails: details,
+		Markers:     []error{ErrInvalidArgument},
+		CreatedAt:   Clock(),
+	}
+	applyDefaultDomain(e)
+	return e
+}
@@ -0,0 +1,64 @@
+package errs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/4nd3r5on/errs"
+)
+
+type fakeFieldError struct {
+	field, tag, param string
+}
+
+func (f fakeFieldError) Field() string { return f.field }
+func (f fakeFieldError) Tag() string   { return f.tag }
+func (f fakeFieldError) Param() string { return f.param }
+
+// fakeValidationErrors mirrors validator.ValidationErrors.
+type fakeValidationErrors []fakeFieldError
+
+func TestFromValidationErrors(t *testing.T) {
+	t.Run("responds 400 with field details", func(t *testing.T) {
+		err := errs.FromValidationErrors(fakeValidationErrors{
+			{field: "Email", tag: "required"},
+			{field: "Age", tag: "min", param: "18"},
+		})
+
+		if !errors.Is(err, errs.ErrInvalidArgument) {
+			t.Error("errors.Is(err, ErrInvalidArgument) = false, want true")
+		}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/users", nil)
+		errs.HandleHTTP(context.Background(), w, r, err, discard)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+		var body struct {
+			Details []errs.FieldError `json:"details"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		want := []errs.FieldError{
+			{Field: "Email", Tag: "required"},
+			{Field: "Age", Tag: "min", Param: "18"},
+		}
+		if !reflect.DeepEqual(body.Details, want) {
+			t.Errorf("details = %+v, want %+v", body.Details, want)
+		}
+	})
+
+	t.Run("returns nil without field errors", func(t *testing.T) {
+		if err := errs.FromValidationErrors(fakeValidationErrors(nil)); err != nil {
+			t.Errorf("FromValidationErrors(nil) = %v, want nil", err)
+		}
+	})
+}